@@ -1,71 +1,258 @@
 // gen.go - Fast test input generator for Fast Blob Indexer
-// Usage: go run gen.go [n] [q] [keylen] > input.txt
-// Default: n=1000000, q=100000, keylen=16
+// Usage:
+//
+//	go run gen.go [flags] > input.txt
+//	go run gen.go [n] [q] [keylen] > input.txt   (positional form)
+//
+// Flags, grouped:
+//
+//	size:      [-n N] [-q Q]
+//	keys:      [-keylen L | -minkeylen A -maxkeylen B] [-alphabet CHARS]
+//	           [-binary-keys] [-adversarial sum|fnv1a] [-dup F]
+//	queries:   [-dist uniform|zipf] [-zipf-s S] [-hit-ratio R]
+//	values:    [-max-size S] [-max-offset O] [-seed S]
+//	output:    [-format text|binary] [-o FILE] [-gzip] [-answers FILE]
+//	resuming:  [-checkpoint FILE [-checkpoint-every R]]
+//
+// Defaults: n=1000000, q=100000, keylen=16, alphabet=a-z, seed=42,
+// dist=uniform, format=text, dup=0, hit-ratio=0.5, max-size=10000,
+// max-offset=1000000.
+//
+// This is a thin wrapper over package gen; see its documentation for the
+// binary (-format=binary) layout.
+//
+// With -checkpoint, every -checkpoint-every records (default 1000000) the
+// -o and -answers files are synced and the progress is saved to FILE. A
+// rerun with the same flags and FILE truncates both files back to the last
+// checkpoint, replays the rng up to it without writing, and appends the
+// rest, so the result is byte-identical to an uninterrupted run. FILE is
+// removed once the run completes. It needs -o and does not work with
+// -gzip, whose stream cannot be resumed from a byte offset.
 
 package main
 
 import (
-	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"math/rand"
+	"io"
 	"os"
 	"strconv"
+
+	"github.com/quadgate/fluxor-blob/challenge/gen"
 )
 
 func main() {
-	n := 1000000
-	q := 100000
-	keyLen := 16
+	cfg := gen.DefaultConfig()
+	flag.IntVar(&cfg.N, "n", cfg.N, "number of blobs")
+	flag.IntVar(&cfg.Q, "q", cfg.Q, "number of queries")
+	flag.IntVar(&cfg.KeyLen, "keylen", cfg.KeyLen, "key length in bytes")
+	flag.IntVar(&cfg.MinKeyLen, "minkeylen", cfg.MinKeyLen, "with -maxkeylen, shortest key length")
+	flag.IntVar(&cfg.MaxKeyLen, "maxkeylen", cfg.MaxKeyLen, "draw each key length uniformly from [minkeylen, maxkeylen] instead of -keylen")
+	flag.StringVar(&cfg.Alphabet, "alphabet", cfg.Alphabet, "UTF-8 characters keys are drawn from; keys with spaces are written quoted")
+	flag.BoolVar(&cfg.BinaryKeys, "binary-keys", cfg.BinaryKeys, "draw key bytes from 0-255, ignoring -alphabet (best with -format=binary)")
+	flag.StringVar(&cfg.Adversarial, "adversarial", cfg.Adversarial, "make stored keys collide under a weak hash: sum or fnv1a")
+	flag.Int64Var(&cfg.Seed, "seed", cfg.Seed, "random seed (same seed => identical output)")
+	flag.StringVar(&cfg.Dist, "dist", cfg.Dist, "query key distribution over stored keys: uniform or zipf")
+	flag.Float64Var(&cfg.ZipfS, "zipf-s", cfg.ZipfS, "zipf skew parameter (must be > 1)")
+	flag.StringVar(&cfg.Format, "format", cfg.Format, "output format: text or binary")
+	flag.Float64Var(&cfg.Dup, "dup", cfg.Dup, "fraction of blobs that reuse an earlier key with fresh size/offset")
+	flag.Float64Var(&cfg.HitRatio, "hit-ratio", cfg.HitRatio, "probability that a query targets an existing key")
+	flag.IntVar(&cfg.MaxSize, "max-size", cfg.MaxSize, "sizes are drawn from [0, max-size)")
+	flag.IntVar(&cfg.MaxOffset, "max-offset", cfg.MaxOffset, "offsets are drawn from [0, max-offset)")
+	answers := flag.String("answers", "", "also write the expected answer for each query to FILE")
+	outPath := flag.String("o", "", "write output to FILE instead of stdout")
+	gz := flag.Bool("gzip", false, "gzip-compress the output")
+	ckptPath := flag.String("checkpoint", "", "save progress to FILE and resume from it when rerun (needs -o)")
+	ckptEvery := flag.Int64("checkpoint-every", 1000000, "with -checkpoint, records between checkpoints")
+	flag.Parse()
+
+	// Positional form kept for backward compatibility: [n] [q] [keylen]
+	args := flag.Args()
+	if len(args) > 3 {
+		usageError(fmt.Sprintf("too many arguments: %q", args[3:]))
+	}
+	for j, dst := range []*int{&cfg.N, &cfg.Q, &cfg.KeyLen}[:len(args)] {
+		v, err := strconv.Atoi(args[j])
+		if err != nil {
+			usageError(fmt.Sprintf("%s: invalid integer %q", [...]string{"n", "q", "keylen"}[j], args[j]))
+		}
+		*dst = v
+	}
+
+	if *ckptPath != "" && (*outPath == "" || *gz) {
+		usageError("-checkpoint needs -o and cannot be combined with -gzip")
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// A saved checkpoint resumes the run it was written by.
+	var ckpt checkpoint
+	if *ckptPath != "" {
+		var err error
+		if ckpt, err = loadCheckpoint(*ckptPath, fingerprint(cfg, *answers != "")); err != nil {
+			fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.Resume = ckpt.Progress
+	}
 
-	if len(os.Args) > 1 {
-		n, _ = strconv.Atoi(os.Args[1])
+	var out io.Writer = os.Stdout
+	var outFile *os.File
+	if *outPath != "" {
+		f, err := openOutput(*outPath, cfg.Resume.Out, cfg.Resume.Records > 0)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+			os.Exit(1)
+		}
+		outFile = f
+		out = f
 	}
-	if len(os.Args) > 2 {
-		q, _ = strconv.Atoi(os.Args[2])
+	var zw *gzip.Writer
+	if *gz {
+		zw = gzip.NewWriter(out)
+		out = zw
 	}
-	if len(os.Args) > 3 {
-		keyLen, _ = strconv.Atoi(os.Args[3])
+
+	var answersFile *os.File
+	if *answers != "" {
+		f, err := openOutput(*answers, cfg.Resume.Answers, cfg.Resume.Records > 0)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+			os.Exit(1)
+		}
+		answersFile = f
+		cfg.Answers = f
 	}
 
-	rng := rand.New(rand.NewSource(42))
-	w := bufio.NewWriterSize(os.Stdout, 1<<20) // 1MB buffer
-	defer w.Flush()
+	if *ckptPath != "" {
+		cfg.ProgressEvery = *ckptEvery
+		cfg.Progress = func(p gen.Progress) error {
+			// The data must be on disk before the checkpoint that counts it.
+			for _, f := range []*os.File{outFile, answersFile} {
+				if f != nil {
+					if err := f.Sync(); err != nil {
+						return err
+					}
+				}
+			}
+			ckpt.Progress = p
+			return ckpt.save(*ckptPath)
+		}
+	}
 
-	// Generate random key
-	key := make([]byte, keyLen)
-	genKey := func() string {
-		for i := range key {
-			key[i] = byte('a' + rng.Intn(26))
+	// Generate flushes its own buffers; close innermost first: the gzip
+	// trailer, then the file. Skipping the gzip Close leaves a truncated
+	// stream.
+	err := gen.Generate(out, cfg)
+	if zw != nil {
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if outFile != nil {
+		if cerr := outFile.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if answersFile != nil {
+		if cerr := answersFile.Close(); err == nil {
+			err = cerr
 		}
-		return string(key)
 	}
+	if err == nil && *ckptPath != "" {
+		err = os.Remove(*ckptPath)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+		os.Exit(1)
+	}
+}
 
-	// Store keys for queries (some will match)
-	keys := make([]string, n)
+// checkpoint is the -checkpoint file: the progress of a run and the
+// fingerprint of the flags it was started with.
+type checkpoint struct {
+	Config string
+	gen.Progress
+}
 
-	// Print N
-	fmt.Fprintln(w, n)
+// fingerprint identifies the output cfg describes, for refusing to resume
+// with different flags.
+func fingerprint(cfg gen.Config, answers bool) string {
+	cfg.Answers, cfg.Progress, cfg.ProgressEvery, cfg.Resume = nil, nil, 0, gen.Progress{}
+	return fmt.Sprintf("%+v answers:%v", cfg, answers)
+}
 
-	// Generate blobs
-	for i := 0; i < n; i++ {
-		k := genKey()
-		keys[i] = k
-		sz := rng.Intn(10000)
-		off := rng.Intn(1000000)
-		fmt.Fprintf(w, "%s %d %d\n", k, sz, off)
+// loadCheckpoint reads the checkpoint at path, or returns a fresh one for
+// config if there is none.
+func loadCheckpoint(path, config string) (checkpoint, error) {
+	c := checkpoint{Config: config}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
 	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("checkpoint %s: %w", path, err)
+	}
+	if c.Config != config {
+		return c, fmt.Errorf("checkpoint %s was written with different flags; remove it to start over", path)
+	}
+	return c, nil
+}
 
-	// Print Q
-	fmt.Fprintln(w, q)
+// save replaces the checkpoint at path, via a rename so an interrupted
+// save leaves the previous one intact.
+func (c checkpoint) save(path string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
 
-	// Generate queries (50% existing keys, 50% random)
-	for i := 0; i < q; i++ {
-		if rng.Intn(2) == 0 && n > 0 {
-			// Existing key
-			fmt.Fprintln(w, keys[rng.Intn(n)])
-		} else {
-			// Random key (likely not found)
-			fmt.Fprintln(w, genKey())
-		}
+// openOutput creates the file at path, or when resuming opens it for
+// appending after its first size bytes, dropping anything written after
+// the checkpoint.
+func openOutput(path string, size int64, resume bool) (*os.File, error) {
+	if !resume {
+		return os.Create(path)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err == nil && fi.Size() < size {
+		err = fmt.Errorf("%s is %d bytes, shorter than the %d the checkpoint counts", path, fi.Size(), size)
 	}
+	if err == nil {
+		err = f.Truncate(size)
+	}
+	if err == nil {
+		_, err = f.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// usageError reports a bad command line and exits with status 2, the same
+// status flag uses for unknown flags.
+func usageError(msg string) {
+	fmt.Fprintf(os.Stderr, "gen: %s\n", msg)
+	flag.Usage()
+	os.Exit(2)
 }
@@ -0,0 +1,162 @@
+package gen
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Adversarial key modes: every stored key collides under the named weak
+// hash, so an indexer using it degrades to one long chain or probe run.
+const (
+	// AdvSum makes all stored keys share one byte sum.
+	AdvSum = "sum"
+	// AdvFNV1a makes all stored keys agree on the low AdvFNV1aBits bits of
+	// 64-bit FNV-1a, which is what a table indexing with h&(size-1) uses.
+	AdvFNV1a = "fnv1a"
+)
+
+// AdvFNV1aBits is how many low FNV-1a bits AdvFNV1a keys share; tables of
+// up to 1<<AdvFNV1aBits slots that mask the hash see a single bucket.
+const AdvFNV1aBits = 16
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+	fnvMask   = 1<<AdvFNV1aBits - 1
+
+	// advRetries bounds consecutive failed attempts at a new key before
+	// Generate gives up rather than spinning on an exhausted key space.
+	advRetries = 1000
+)
+
+// adversary returns a generator of distinct keys of cfg.KeyLen symbols that
+// all collide under cfg.Adversarial.
+func adversary(cfg Config, rng *rand.Rand, syms []string) func() (string, error) {
+	seen := make(map[string]bool)
+	var collide func() (string, bool)
+	switch cfg.Adversarial {
+	case AdvSum:
+		collide = sumCollider(cfg.KeyLen, rng, syms)
+	case AdvFNV1a:
+		collide = fnvCollider(cfg.KeyLen, rng, syms)
+	}
+	return func() (string, error) {
+		for try := 0; try < advRetries; try++ {
+			if k, ok := collide(); ok && !seen[k] {
+				seen[k] = true
+				return k, nil
+			}
+		}
+		return "", fmt.Errorf("gen: no new %s-colliding key after %d tries (%d found); use a longer keylen or larger alphabet",
+			cfg.Adversarial, advRetries, len(seen))
+	}
+}
+
+// sumCollider draws a random key and greedily swaps symbols until its byte
+// sum matches that of the first key drawn.
+func sumCollider(n int, rng *rand.Rand, syms []string) func() (string, bool) {
+	weight := make([]int, len(syms))
+	for j, s := range syms {
+		for k := 0; k < len(s); k++ {
+			weight[j] += int(s[k])
+		}
+	}
+	idx := make([]int, n)
+	draw := func() int {
+		sum := 0
+		for p := range idx {
+			idx[p] = rng.Intn(len(syms))
+			sum += weight[idx[p]]
+		}
+		return sum
+	}
+	target := draw()
+	first := true
+	return func() (string, bool) {
+		sum := target
+		if !first {
+			sum = draw()
+		}
+		first = false
+		for step := 0; step < 8*n && sum != target; step++ {
+			p := rng.Intn(n)
+			need := target - sum + weight[idx[p]]
+			best := idx[p]
+			for j, w := range weight {
+				if abs(need-w) < abs(need-weight[best]) {
+					best = j
+				}
+			}
+			sum += weight[best] - weight[idx[p]]
+			idx[p] = best
+		}
+		return symbolKey(syms, idx), sum == target
+	}
+}
+
+// fnvCollider picks a random low-bit FNV-1a target, then builds keys as a
+// random prefix plus a suffix chosen to steer the hash onto the target.
+// The low bits of FNV-1a depend only on the low bits of the state and the
+// step is invertible there, so suffixes are indexed by the state they need.
+func fnvCollider(n int, rng *rand.Rand, syms []string) func() (string, bool) {
+	// Suffix length: enough symbols that 1<<20 random suffixes rarely
+	// repeat, covering nearly every one of the 1<<16 states.
+	sl := 1
+	for c := len(syms); c < 1<<20 && sl < n; c *= len(syms) {
+		sl++
+	}
+	target := rng.Uint64() & fnvMask
+	inv := uint64(fnvPrime)
+	for j := 0; j < 6; j++ { // Newton iteration for the inverse mod 2^64
+		inv *= 2 - fnvPrime*inv
+	}
+	suffix := make([][]int, fnvMask+1)
+	idx := make([]int, sl)
+	for s := 0; s < 1<<20; s++ {
+		for p := range idx {
+			idx[p] = rng.Intn(len(syms))
+		}
+		h := target
+		for p := sl - 1; p >= 0; p-- {
+			sym := syms[idx[p]]
+			for k := len(sym) - 1; k >= 0; k-- {
+				h = (h*inv ^ uint64(sym[k])) & fnvMask
+			}
+		}
+		if suffix[h] == nil {
+			suffix[h] = append([]int(nil), idx...)
+		}
+	}
+	idx = make([]int, n)
+	return func() (string, bool) {
+		h := uint64(fnvOffset)
+		for p := 0; p < n-sl; p++ {
+			idx[p] = rng.Intn(len(syms))
+			sym := syms[idx[p]]
+			for k := 0; k < len(sym); k++ {
+				h = (h ^ uint64(sym[k])) * fnvPrime
+			}
+		}
+		s := suffix[h&fnvMask]
+		if s == nil {
+			return "", false
+		}
+		copy(idx[n-sl:], s)
+		return symbolKey(syms, idx), true
+	}
+}
+
+func symbolKey(syms []string, idx []int) string {
+	var b []byte
+	for _, j := range idx {
+		b = append(b, syms[j]...)
+	}
+	return string(b)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
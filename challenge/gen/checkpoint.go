@@ -0,0 +1,53 @@
+package gen
+
+import (
+	"io"
+	"math/rand"
+)
+
+// Progress is a point Generate can resume from. Records counts the output
+// records completed so far: the N line, the N blobs, the Q line and the Q
+// queries, in that order. Draws is the number of values taken from the
+// seeded source by then, which pins the rng state, and Out and Answers
+// are the bytes written to the output and answers writers up to that
+// record.
+type Progress struct {
+	Records int64
+	Draws   uint64
+	Out     int64
+	Answers int64
+}
+
+// countingSource is a rand.Source64 that counts the values drawn from it.
+// Wrapping the seeded source does not change what rand.Rand makes of it.
+type countingSource struct {
+	src   rand.Source64
+	draws uint64
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.draws = 0
+}
+
+// countWriter counts the bytes written through it.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
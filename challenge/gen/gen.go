@@ -0,0 +1,438 @@
+// Package gen generates reproducible test input for the Fast Blob Indexer.
+//
+// Text format (FormatText):
+//
+//	N
+//	N lines: key size offset
+//	Q
+//	Q lines: key
+//
+// Keys that would not survive whitespace splitting (they contain spaces or
+// other control bytes, start with '"', or are exactly "+" or "-") are
+// written as Go double-quoted strings, which the Go indexer's reader
+// unquotes.
+//
+// Binary format (FormatBinary), all integers little-endian:
+//
+//	uint32 N
+//	N times: uint8 keylen, keylen key bytes, uint32 size, uint32 offset
+//	uint32 Q
+//	Q times: uint8 keylen, keylen key bytes
+//
+// The binary layout keeps 32-bit fields, so it requires MaxSize and
+// MaxOffset of at most 1<<32; use the text format for larger values.
+package gen
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Query key distributions.
+const (
+	DistUniform = "uniform"
+	DistZipf    = "zipf"
+)
+
+// Output formats.
+const (
+	FormatText   = "text"
+	FormatBinary = "binary"
+)
+
+// Config controls what Generate emits. Start from DefaultConfig; the zero
+// value is not useful on its own.
+type Config struct {
+	N      int   // number of blobs
+	Q      int   // number of queries
+	KeyLen int   // key length in alphabet symbols (bytes for ASCII alphabets)
+	Seed   int64 // same seed => identical output
+
+	// MinKeyLen and MaxKeyLen, when MaxKeyLen > 0, replace the fixed KeyLen
+	// with a length drawn uniformly from [MinKeyLen, MaxKeyLen] per key.
+	MinKeyLen, MaxKeyLen int
+
+	// Alphabet is the UTF-8 character set key symbols are drawn from
+	// uniformly. Multibyte characters are kept whole, so keys stay valid
+	// UTF-8.
+	Alphabet string
+	// Adversarial, if set to AdvSum or AdvFNV1a, makes every stored key a
+	// distinct key colliding under that weak hash. It needs a fixed KeyLen.
+	// Miss queries stay random.
+	Adversarial string
+
+	// BinaryKeys draws every key byte from the full 0-255 range instead,
+	// ignoring Alphabet. FormatBinary carries such keys as is; the text
+	// format quotes the ones that need it.
+	BinaryKeys bool
+
+	Dist     string  // query key distribution over stored keys: DistUniform or DistZipf
+	ZipfS    float64 // zipf skew parameter (must be > 1)
+	HitRatio float64 // probability that a query targets an existing key
+	Dup      float64 // fraction of blobs that reuse an earlier key with fresh size/offset
+	Format   string  // FormatText or FormatBinary
+
+	MaxSize   int // sizes are drawn uniformly from [0, MaxSize)
+	MaxOffset int // offsets are drawn uniformly from [0, MaxOffset)
+
+	// Answers, if non-nil, receives the expected answer for each query in
+	// query order, in the format the indexer prints: "size offset" or
+	// "NOTFOUND".
+	Answers io.Writer
+
+	// Progress, if non-nil, is called after every ProgressEvery records
+	// with the output and answers flushed, so every byte it counts has
+	// reached its writer. An error from it stops Generate.
+	Progress      func(Progress) error
+	ProgressEvery int64
+
+	// Resume continues a run interrupted after Resume.Records records.
+	// The records before that point are replayed for their rng draws and
+	// key state but not written, so dst and Answers should already hold
+	// Resume.Out and Resume.Answers bytes and only get the rest. Generate
+	// fails if the replay's draw count is not Resume.Draws, which means
+	// the config differs from the one the progress was recorded with.
+	Resume Progress
+}
+
+// DefaultAlphabet is the key byte set of the original generator.
+const DefaultAlphabet = "abcdefghijklmnopqrstuvwxyz"
+
+// DefaultConfig returns the generator defaults: n=1000000, q=100000,
+// keylen=16, seed=42, lowercase a-z keys, uniform queries with a 50% hit
+// ratio, no duplicates, text output, sizes below 10000 and offsets below
+// 1000000.
+func DefaultConfig() Config {
+	return Config{
+		N:        1000000,
+		Q:        100000,
+		KeyLen:   16,
+		Seed:     42,
+		Alphabet: DefaultAlphabet,
+		Dist:     DistUniform,
+		ZipfS:    1.1,
+		HitRatio: 0.5,
+		Dup:      0,
+		Format:   FormatText,
+
+		MaxSize:   10000,
+		MaxOffset: 1000000,
+	}
+}
+
+// Validate reports the first invalid field in cfg.
+func (cfg Config) Validate() error {
+	if cfg.N < 0 || cfg.Q < 0 || cfg.KeyLen < 0 {
+		return fmt.Errorf("gen: n, q and keylen must be >= 0, got %d, %d, %d", cfg.N, cfg.Q, cfg.KeyLen)
+	}
+	if cfg.MinKeyLen < 0 || cfg.MaxKeyLen < 0 {
+		return fmt.Errorf("gen: minkeylen and maxkeylen must be >= 0, got %d, %d", cfg.MinKeyLen, cfg.MaxKeyLen)
+	}
+	if cfg.MinKeyLen > 0 && cfg.MaxKeyLen == 0 {
+		return fmt.Errorf("gen: minkeylen needs maxkeylen")
+	}
+	if cfg.MinKeyLen > cfg.MaxKeyLen && cfg.MaxKeyLen > 0 {
+		return fmt.Errorf("gen: minkeylen %d exceeds maxkeylen %d", cfg.MinKeyLen, cfg.MaxKeyLen)
+	}
+	switch cfg.Adversarial {
+	case "":
+	case AdvSum, AdvFNV1a:
+		if cfg.MaxKeyLen > 0 || cfg.KeyLen == 0 {
+			return fmt.Errorf("gen: adversarial keys need a fixed keylen > 0")
+		}
+	default:
+		return fmt.Errorf("gen: unknown adversarial hash %q (want sum or fnv1a)", cfg.Adversarial)
+	}
+	if !cfg.BinaryKeys && (cfg.Alphabet == "" || !utf8.ValidString(cfg.Alphabet)) {
+		return fmt.Errorf("gen: alphabet must be non-empty UTF-8, got %q", cfg.Alphabet)
+	}
+	switch cfg.Dist {
+	case DistUniform:
+	case DistZipf:
+		// rand.NewZipf returns nil for s <= 1; reject it up front instead.
+		if !(cfg.ZipfS > 1) {
+			return fmt.Errorf("gen: zipf-s must be > 1, got %v", cfg.ZipfS)
+		}
+	default:
+		return fmt.Errorf("gen: unknown dist %q (want uniform or zipf)", cfg.Dist)
+	}
+	if cfg.MaxSize <= 0 || cfg.MaxOffset <= 0 {
+		return fmt.Errorf("gen: max-size and max-offset must be > 0, got %d, %d", cfg.MaxSize, cfg.MaxOffset)
+	}
+	switch cfg.Format {
+	case FormatText:
+	case FormatBinary:
+		// Keys are length-prefixed with a single byte.
+		if n := cfg.maxKeyLen() * cfg.maxSymbolLen(); n > 255 {
+			return fmt.Errorf("gen: binary format needs keys of at most 255 bytes, keylen %d allows %d", cfg.maxKeyLen(), n)
+		}
+		if cfg.MaxSize > 1<<32 || cfg.MaxOffset > 1<<32 {
+			return fmt.Errorf("gen: binary format needs max-size and max-offset <= 1<<32, got %d, %d", cfg.MaxSize, cfg.MaxOffset)
+		}
+	default:
+		return fmt.Errorf("gen: unknown format %q (want text or binary)", cfg.Format)
+	}
+	if !(cfg.Dup >= 0 && cfg.Dup <= 1) {
+		return fmt.Errorf("gen: dup must be in [0,1], got %v", cfg.Dup)
+	}
+	if !(cfg.HitRatio >= 0 && cfg.HitRatio <= 1) {
+		return fmt.Errorf("gen: hit-ratio must be in [0,1], got %v", cfg.HitRatio)
+	}
+	if cfg.Progress != nil && cfg.ProgressEvery <= 0 {
+		return fmt.Errorf("gen: progress interval must be > 0, got %d", cfg.ProgressEvery)
+	}
+	if total := 2 + int64(cfg.N) + int64(cfg.Q); cfg.Resume.Records < 0 || cfg.Resume.Records > total {
+		return fmt.Errorf("gen: resume point %d outside the %d records", cfg.Resume.Records, total)
+	}
+	return nil
+}
+
+// maxKeyLen is the longest key in symbols.
+func (cfg Config) maxKeyLen() int {
+	if cfg.MaxKeyLen > 0 {
+		return cfg.MaxKeyLen
+	}
+	return cfg.KeyLen
+}
+
+// maxSymbolLen is the longest key symbol in bytes.
+func (cfg Config) maxSymbolLen() int {
+	if cfg.BinaryKeys {
+		return 1
+	}
+	n := 1
+	for _, r := range cfg.Alphabet {
+		n = max(n, utf8.RuneLen(r))
+	}
+	return n
+}
+
+// Generate writes a corpus described by cfg to dst. Output is buffered
+// internally and flushed before Generate returns.
+func Generate(dst io.Writer, cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	// Counting draws leaves the stream as is but records the rng state for
+	// Progress.
+	src := &countingSource{src: rand.NewSource(cfg.Seed).(rand.Source64)}
+	rng := rand.New(src)
+	out := &countWriter{w: dst}
+	w := bufio.NewWriterSize(out, 1<<20) // 1MB buffer
+
+	var aw *bufio.Writer
+	var ans *countWriter
+	if cfg.Answers != nil {
+		ans = &countWriter{w: cfg.Answers}
+		aw = bufio.NewWriterSize(ans, 1<<20)
+	}
+
+	// rec counts the records generated so far. Those before
+	// cfg.Resume.Records are replayed without being written.
+	var rec int64
+	emit := func() bool { return rec >= cfg.Resume.Records }
+	progress := func() error {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		p := Progress{Records: rec, Draws: src.draws, Out: cfg.Resume.Out + out.n}
+		if aw != nil {
+			if err := aw.Flush(); err != nil {
+				return err
+			}
+			p.Answers = cfg.Resume.Answers + ans.n
+		}
+		return cfg.Progress(p)
+	}
+	// done finishes a record.
+	done := func() error {
+		rec++
+		if rec == cfg.Resume.Records && src.draws != cfg.Resume.Draws {
+			return fmt.Errorf("gen: resume point %d: replay took %d rng draws, want %d; the config has changed", rec, src.draws, cfg.Resume.Draws)
+		}
+		if cfg.Progress == nil || rec <= cfg.Resume.Records || rec%cfg.ProgressEvery != 0 {
+			return nil
+		}
+		return progress()
+	}
+
+	// Key symbols: one per alphabet character (whole UTF-8 sequences), or
+	// every byte value for BinaryKeys. One rng draw per symbol, so the
+	// default alphabet reproduces the original a-z stream.
+	var syms []string
+	if cfg.BinaryKeys {
+		for b := 0; b < 256; b++ {
+			syms = append(syms, string([]byte{byte(b)}))
+		}
+	} else {
+		for _, r := range cfg.Alphabet {
+			syms = append(syms, string(r))
+		}
+	}
+
+	// Generate random key. The length draw only happens for ranged keys so
+	// fixed-length output is unchanged.
+	key := make([]byte, 0, cfg.maxKeyLen()*cfg.maxSymbolLen())
+	genKey := func() string {
+		n := cfg.KeyLen
+		if cfg.MaxKeyLen > 0 {
+			n = cfg.MinKeyLen + rng.Intn(cfg.MaxKeyLen-cfg.MinKeyLen+1)
+		}
+		key = key[:0]
+		for i := 0; i < n; i++ {
+			key = append(key, syms[rng.Intn(len(syms))]...)
+		}
+		return string(key)
+	}
+
+	// New stored keys: random, or colliding ones in adversarial mode
+	newKey := func() (string, error) { return genKey(), nil }
+	if cfg.Adversarial != "" {
+		newKey = adversary(cfg, rng, syms)
+	}
+
+	// Record writers for the selected output format
+	var buf []byte
+	writeCount := func(c int) { fmt.Fprintln(w, c) }
+	writeBlob := func(k string, sz, off int) { fmt.Fprintf(w, "%s %d %d\n", textKey(k), sz, off) }
+	writeQuery := func(k string) { fmt.Fprintln(w, textKey(k)) }
+	if cfg.Format == FormatBinary {
+		le := binary.LittleEndian
+		writeCount = func(c int) {
+			w.Write(le.AppendUint32(buf[:0], uint32(c)))
+		}
+		writeBlob = func(k string, sz, off int) {
+			buf = append(buf[:0], byte(len(k)))
+			buf = append(buf, k...)
+			buf = le.AppendUint32(buf, uint32(sz))
+			buf = le.AppendUint32(buf, uint32(off))
+			w.Write(buf)
+		}
+		writeQuery = func(k string) {
+			buf = append(buf[:0], byte(len(k)))
+			buf = append(buf, k...)
+			w.Write(buf)
+		}
+	}
+
+	// Store distinct keys for queries (some will match)
+	keys := make([]string, 0, cfg.N)
+
+	// Latest metadata per key, only kept when answers are requested.
+	// Repeated keys overwrite, matching last-write-wins in the indexer.
+	type meta struct{ size, off int }
+	var latest map[string]meta
+	if aw != nil {
+		latest = make(map[string]meta, cfg.N)
+	}
+
+	// Print N
+	if emit() {
+		writeCount(cfg.N)
+	}
+	if err := done(); err != nil {
+		return err
+	}
+
+	// Generate blobs
+	for i := 0; i < cfg.N; i++ {
+		var k string
+		// Only draw when Dup is set so Dup=0 keeps the original stream.
+		if cfg.Dup > 0 && i > 0 && rng.Float64() < cfg.Dup {
+			// Overwrite an earlier key
+			k = keys[rng.Intn(len(keys))]
+		} else {
+			var err error
+			if k, err = newKey(); err != nil {
+				return err
+			}
+			keys = append(keys, k)
+		}
+		sz := rng.Intn(cfg.MaxSize)
+		off := rng.Intn(cfg.MaxOffset)
+		if emit() {
+			writeBlob(k, sz, off)
+		}
+		if latest != nil {
+			latest[k] = meta{sz, off}
+		}
+		if err := done(); err != nil {
+			return err
+		}
+	}
+
+	// Pick a stored key index for a hit query. Zipf favours low indices,
+	// so the first few stored keys become the hot set.
+	pick := func() int { return rng.Intn(len(keys)) }
+	if cfg.Dist == DistZipf && len(keys) > 0 {
+		z := rand.NewZipf(rng, cfg.ZipfS, 1, uint64(len(keys)-1))
+		pick = func() int { return int(z.Uint64()) }
+	}
+
+	// Decide whether a query targets an existing key. The default 0.5
+	// keeps the original coin flip so existing corpora stay reproducible.
+	hit := func() bool { return rng.Float64() < cfg.HitRatio }
+	if cfg.HitRatio == 0.5 {
+		hit = func() bool { return rng.Intn(2) == 0 }
+	}
+
+	// Print Q
+	if emit() {
+		writeCount(cfg.Q)
+	}
+	if err := done(); err != nil {
+		return err
+	}
+
+	// Generate queries (HitRatio existing keys, the rest random)
+	for i := 0; i < cfg.Q; i++ {
+		var k string
+		if hit() && len(keys) > 0 {
+			// Existing key
+			k = keys[pick()]
+		} else {
+			// Random key (likely not found)
+			k = genKey()
+		}
+		if emit() {
+			writeQuery(k)
+			if aw != nil {
+				if m, ok := latest[k]; ok {
+					fmt.Fprintf(aw, "%d %d\n", m.size, m.off)
+				} else {
+					fmt.Fprintln(aw, "NOTFOUND")
+				}
+			}
+		}
+		if err := done(); err != nil {
+			return err
+		}
+	}
+
+	if aw != nil {
+		if err := aw.Flush(); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// textKey returns k as it appears in the text format, quoting it when it
+// would otherwise be split or misread.
+func textKey(k string) string {
+	if k == "" || k == "+" || k == "-" || k[0] == '"' {
+		return strconv.Quote(k)
+	}
+	for j := 0; j < len(k); j++ {
+		if k[j] <= ' ' || k[j] == 0x7f {
+			return strconv.Quote(k)
+		}
+	}
+	return k
+}
@@ -0,0 +1,239 @@
+package gen
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func small(seed int64) Config {
+	cfg := DefaultConfig()
+	cfg.N, cfg.Q, cfg.KeyLen, cfg.Seed = 100, 50, 8, seed
+	return cfg
+}
+
+func TestGenerateReproducible(t *testing.T) {
+	var a, b, c bytes.Buffer
+	if err := Generate(&a, small(42)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Generate(&b, small(42)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Generate(&c, small(7)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Fatal("same seed produced different output")
+	}
+	if bytes.Equal(a.Bytes(), c.Bytes()) {
+		t.Fatal("different seeds produced identical output")
+	}
+	if lines := strings.Count(a.String(), "\n"); lines != 1+100+1+50 {
+		t.Fatalf("got %d lines, want %d", lines, 152)
+	}
+}
+
+func TestGenerateAnswers(t *testing.T) {
+	var out, ans bytes.Buffer
+	cfg := small(42)
+	cfg.Dup = 0.5
+	cfg.Answers = &ans
+	if err := Generate(&out, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(ans.String(), "\n"); lines != cfg.Q {
+		t.Fatalf("got %d answer lines, want %d", lines, cfg.Q)
+	}
+}
+
+func TestGenerateAlphabet(t *testing.T) {
+	tests := []struct {
+		alphabet string
+		binary   bool
+		check    func(string) bool
+	}{
+		{"0123456789abcdef", false, func(k string) bool {
+			return strings.Trim(k, "0123456789abcdef") == "" && len(k) == 8
+		}},
+		{"äöü", false, func(k string) bool {
+			return utf8.ValidString(k) && utf8.RuneCountInString(k) == 8 && strings.Trim(k, "äöü") == ""
+		}},
+		{"", true, func(k string) bool { return len(k) == 8 }},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		cfg := small(42)
+		cfg.Alphabet, cfg.BinaryKeys, cfg.Format = tt.alphabet, tt.binary, FormatBinary
+		if err := Generate(&out, cfg); err != nil {
+			t.Fatal(err)
+		}
+		// First record: uint32 N, then the length-prefixed key.
+		b := out.Bytes()[4:]
+		if k := string(b[1 : 1+b[0]]); !tt.check(k) {
+			t.Errorf("alphabet %q binary %v: bad key %q", tt.alphabet, tt.binary, k)
+		}
+	}
+}
+
+func TestGenerateKeyLenRange(t *testing.T) {
+	var out bytes.Buffer
+	cfg := small(42)
+	cfg.MinKeyLen, cfg.MaxKeyLen = 2, 6
+	if err := Generate(&out, cfg); err != nil {
+		t.Fatal(err)
+	}
+	lens := map[int]bool{}
+	lines := strings.Split(out.String(), "\n")
+	for _, l := range lines[1 : 1+cfg.N] {
+		k := l[:strings.IndexByte(l, ' ')]
+		if len(k) < 2 || len(k) > 6 {
+			t.Fatalf("key %q outside [2, 6]", k)
+		}
+		lens[len(k)] = true
+	}
+	if len(lens) != 5 {
+		t.Fatalf("saw key lengths %v, want all of 2..6", lens)
+	}
+}
+
+func TestGenerateAdversarial(t *testing.T) {
+	hashes := map[string]func(string) uint64{
+		AdvSum: func(k string) uint64 {
+			var h uint64
+			for j := 0; j < len(k); j++ {
+				h += uint64(k[j])
+			}
+			return h
+		},
+		AdvFNV1a: func(k string) uint64 {
+			h := uint64(fnvOffset)
+			for j := 0; j < len(k); j++ {
+				h = (h ^ uint64(k[j])) * fnvPrime
+			}
+			return h & fnvMask
+		},
+	}
+	for _, name := range []string{AdvSum, AdvFNV1a} {
+		var out bytes.Buffer
+		cfg := small(42)
+		cfg.N, cfg.Adversarial = 2000, name
+		if err := Generate(&out, cfg); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		lines := strings.Split(out.String(), "\n")
+		seen := map[string]bool{}
+		var want uint64
+		for j, l := range lines[1 : 1+cfg.N] {
+			k := l[:strings.IndexByte(l, ' ')]
+			if len(k) != cfg.KeyLen || strings.Trim(k, DefaultAlphabet) != "" {
+				t.Fatalf("%s: invalid key %q", name, k)
+			}
+			if h := hashes[name](k); j == 0 {
+				want = h
+			} else if h != want {
+				t.Fatalf("%s: key %q hashes to %d, want %d", name, k, h, want)
+			}
+			seen[k] = true
+		}
+		if len(seen) != cfg.N {
+			t.Fatalf("%s: %d distinct keys, want %d", name, len(seen), cfg.N)
+		}
+	}
+}
+
+func TestTextKey(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"abc", "abc"},
+		{"hello world", `"hello world"`},
+		{`"x`, `"\"x"`},
+		{"+", `"+"`},
+		{"", `""`},
+		{`a\b`, `a\b`},
+		{"a\x00b", `"a\x00b"`},
+		{"\xff", "\xff"},
+	}
+	for _, tt := range tests {
+		if got := textKey(tt.in); got != tt.want {
+			t.Errorf("textKey(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		mod  func(*Config)
+	}{
+		{"negative n", func(c *Config) { c.N = -1 }},
+		{"minkeylen without max", func(c *Config) { c.MinKeyLen = 3 }},
+		{"minkeylen above max", func(c *Config) { c.MinKeyLen, c.MaxKeyLen = 8, 4 }},
+		{"binary long ranged key", func(c *Config) { c.Format, c.MaxKeyLen = FormatBinary, 300 }},
+		{"unknown adversarial", func(c *Config) { c.Adversarial = "md5" }},
+		{"adversarial ranged keys", func(c *Config) { c.Adversarial, c.MaxKeyLen = AdvSum, 8 }},
+		{"empty alphabet", func(c *Config) { c.Alphabet = "" }},
+		{"invalid utf-8 alphabet", func(c *Config) { c.Alphabet = "\xff" }},
+		{"binary multibyte key too long", func(c *Config) { c.Format, c.KeyLen, c.Alphabet = FormatBinary, 200, "ä" }},
+		{"zipf s <= 1", func(c *Config) { c.Dist, c.ZipfS = DistZipf, 1 }},
+		{"unknown dist", func(c *Config) { c.Dist = "normal" }},
+		{"unknown format", func(c *Config) { c.Format = "xml" }},
+		{"binary long key", func(c *Config) { c.Format, c.KeyLen = FormatBinary, 256 }},
+		{"dup out of range", func(c *Config) { c.Dup = 1.5 }},
+		{"hit ratio out of range", func(c *Config) { c.HitRatio = -0.1 }},
+		{"zero max size", func(c *Config) { c.MaxSize = 0 }},
+		{"binary wide offset", func(c *Config) { c.Format, c.MaxOffset = FormatBinary, 1<<33 }},
+	}
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("default config: %v", err)
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		tt.mod(&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestGenerateResume(t *testing.T) {
+	cfg := small(42)
+	cfg.N, cfg.Q, cfg.Dup, cfg.Dist = 5000, 3000, 0.3, DistZipf
+	var full, fullAns bytes.Buffer
+	cfg.Answers = &fullAns
+	var points []Progress
+	cfg.ProgressEvery = 1000
+	cfg.Progress = func(p Progress) error {
+		points = append(points, p)
+		return nil
+	}
+	if err := Generate(&full, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != (2+5000+3000)/1000 {
+		t.Fatalf("got %d progress points, want %d", len(points), (2+5000+3000)/1000)
+	}
+
+	// Resuming from any point, in the blobs or in the queries, with the
+	// output cut back to it writes exactly the rest.
+	for _, p := range []Progress{points[2], points[6]} {
+		out := bytes.NewBuffer(append([]byte(nil), full.Bytes()[:p.Out]...))
+		ans := bytes.NewBuffer(append([]byte(nil), fullAns.Bytes()[:p.Answers]...))
+		rcfg := cfg
+		rcfg.Answers, rcfg.Progress, rcfg.Resume = ans, nil, p
+		if err := Generate(out, rcfg); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out.Bytes(), full.Bytes()) || !bytes.Equal(ans.Bytes(), fullAns.Bytes()) {
+			t.Fatalf("resume from record %d differs from the uninterrupted run", p.Records)
+		}
+	}
+
+	// A different config cannot pick up the progress.
+	rcfg := cfg
+	rcfg.Progress, rcfg.Answers, rcfg.Resume, rcfg.Dup = nil, nil, points[2], 0.5
+	if err := Generate(io.Discard, rcfg); err == nil || !strings.Contains(err.Error(), "rng draws") {
+		t.Fatalf("changed config: err = %v, want a draw count mismatch", err)
+	}
+}
@@ -0,0 +1,67 @@
+package index
+
+import "unsafe"
+
+// arenaChunk is the size of one key arena buffer. Keys longer than this get
+// a chunk of their own.
+const arenaChunk = 1 << 20
+
+// keyRef locates a key's bytes in a keyArena.
+type keyRef struct {
+	chunk uint32
+	off   uint32
+	n     uint32
+}
+
+// keyArena stores key bytes back to back in a few large buffers, so an
+// index holds no per-key string allocations: records and table slots
+// refer to keys by keyRef and contain no pointers, and the GC has nothing
+// to scan in them. Bytes are only ever appended, so a string viewing them
+// stays valid for as long as it is referenced.
+type keyArena struct {
+	chunks [][]byte
+}
+
+// add copies key into the arena.
+func (a *keyArena) add(key string) keyRef {
+	last := len(a.chunks) - 1
+	if last < 0 || len(a.chunks[last])+len(key) > cap(a.chunks[last]) {
+		a.chunks = append(a.chunks, make([]byte, 0, max(arenaChunk, len(key))))
+		last++
+	}
+	c := a.chunks[last]
+	ref := keyRef{chunk: uint32(last), off: uint32(len(c)), n: uint32(len(key))}
+	a.chunks[last] = append(c, key...)
+	return ref
+}
+
+// addKey returns the arena reference for a key new to the table: the
+// interned copy if WithInterning has seen key before, or a fresh one.
+func (i *Index) addKey(key string) keyRef {
+	if i.intern == nil {
+		return i.keys.add(key)
+	}
+	if ref, ok := i.intern[key]; ok {
+		return ref
+	}
+	ref := i.keys.add(key)
+	i.intern[i.keys.str(ref)] = ref
+	return ref
+}
+
+// str returns the key at ref without copying.
+func (a *keyArena) str(ref keyRef) string {
+	if ref.n == 0 {
+		return ""
+	}
+	return unsafe.String(&a.chunks[ref.chunk][ref.off], ref.n)
+}
+
+// bytes reports the key bytes stored and the bytes the chunks reserve.
+func (a *keyArena) bytes() (used, reserved int64) {
+	for _, c := range a.chunks {
+		used += int64(len(c))
+		reserved += int64(cap(c))
+	}
+	return used, reserved
+}
@@ -0,0 +1,48 @@
+package index
+
+import (
+	"runtime"
+	"testing"
+)
+
+// BenchmarkGC times a forced full collection (ns/op, mostly marking) with a
+// 1M-key index live, against the same data held as a map of individually
+// allocated strings. pause-ns/op is the stop-the-world share reported by
+// runtime.MemStats.
+func BenchmarkGC(b *testing.B) {
+	bench := func(b *testing.B, build func(blobs []string) any) {
+		blobs, _ := corpus(b, 1000000, 0)
+		live := build(blobs)
+		blobs = nil
+		runtime.GC()
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			runtime.GC()
+		}
+		b.StopTimer()
+		runtime.ReadMemStats(&after)
+		b.ReportMetric(float64(after.PauseTotalNs-before.PauseTotalNs)/float64(b.N), "pause-ns/op")
+		b.ReportMetric(float64(after.HeapAlloc)/(1<<20), "heap-MB")
+		runtime.KeepAlive(live)
+	}
+	b.Run("index", func(b *testing.B) {
+		bench(b, func(blobs []string) any {
+			idx := NewWithCapacity(len(blobs))
+			for n, k := range blobs {
+				idx.Insert(string([]byte(k)), uint64(n), uint64(n))
+			}
+			return idx
+		})
+	})
+	b.Run("stringmap", func(b *testing.B) {
+		bench(b, func(blobs []string) any {
+			m := make(map[string][2]uint64, len(blobs))
+			for n, k := range blobs {
+				m[string([]byte(k))] = [2]uint64{uint64(n), uint64(n)}
+			}
+			return m
+		})
+	})
+}
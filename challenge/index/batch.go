@@ -0,0 +1,78 @@
+package index
+
+import "context"
+
+// batchBlock is how many keys GetBatch hashes ahead of probing.
+const batchBlock = 64
+
+// GetBatch answers keys[j] into out[j]. out must be pre-sized by the
+// caller to at least len(keys); GetBatch panics otherwise. Keys are hashed
+// a block at a time before the table is probed, so the hashing loop and
+// the probing loop each stay tight. Like Get, it only reads the index.
+func (i *Index) GetBatch(keys []string, out []Result) {
+	if len(out) < len(keys) {
+		panic("index: GetBatch out shorter than keys")
+	}
+	if i.m != nil {
+		for j, k := range keys {
+			out[j].Size, out[j].Offset, out[j].Found = i.m.get(k)
+		}
+		return
+	}
+	if i.normalize != nil || i.expires != nil {
+		// Expired keys read as misses; only Get evicts them.
+		for j, k := range keys {
+			if k = i.norm(k); i.expired(k) {
+				out[j] = Result{}
+				continue
+			}
+			out[j].Size, out[j].Offset, out[j].Found = i.lookup(k)
+		}
+		return
+	}
+	if i.shards != nil {
+		for j, k := range keys {
+			h := i.hash(k)
+			out[j].Size, out[j].Offset, out[j].Found = i.shardOf(h).getHashed(k, h)
+		}
+		return
+	}
+	var hashes [batchBlock]uint64
+	for lo := 0; lo < len(keys); lo += batchBlock {
+		block := keys[lo:min(lo+batchBlock, len(keys))]
+		res := out[lo : lo+len(block)]
+		for j, k := range block {
+			hashes[j] = i.hash(k)
+		}
+		for j, k := range block {
+			if i.bloom != nil && !i.bloom.mayContain(hashes[j]) {
+				res[j] = Result{}
+				continue
+			}
+			p, ok := i.findHashed(k, hashes[j])
+			if !ok {
+				res[j] = Result{}
+				continue
+			}
+			r := &i.records[p]
+			res[j] = Result{Size: r.size, Offset: r.offset, Found: true}
+		}
+	}
+}
+
+// GetBatchCtx is GetBatch in chunks of a few thousand keys, checking ctx
+// between chunks. On cancellation it returns ctx.Err(); out is then only
+// filled up to some chunk boundary.
+func (i *Index) GetBatchCtx(ctx context.Context, keys []string, out []Result) error {
+	if len(out) < len(keys) {
+		panic("index: GetBatchCtx out shorter than keys")
+	}
+	for lo := 0; lo < len(keys); lo += ctxCheckEvery {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		hi := min(lo+ctxCheckEvery, len(keys))
+		i.GetBatch(keys[lo:hi], out[lo:hi])
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package index
+
+import "testing"
+
+func TestGetBatch(t *testing.T) {
+	blobs, queries := corpus(t, 10000, 5000)
+	for _, idx := range []*Index{NewWithCapacity(len(blobs)), NewWithBloom(len(blobs), 0.01)} {
+		for n, k := range blobs {
+			idx.Insert(k, uint64(n), uint64(n*3))
+		}
+		out := make([]Result, len(queries))
+		idx.GetBatch(queries, out)
+		for j, k := range queries {
+			size, offset, ok := idx.Get(k)
+			if out[j] != (Result{size, offset, ok}) {
+				t.Fatalf("out[%d] = %+v, want %d %d %v", j, out[j], size, offset, ok)
+			}
+		}
+	}
+}
+
+// BenchmarkGetBatch compares GetBatch against calling Get in a loop over
+// the same queries.
+func BenchmarkGetBatch(b *testing.B) {
+	blobs, queries := corpus(b, 1000000, 100000)
+	idx := NewWithCapacity(len(blobs))
+	for n, k := range blobs {
+		idx.Insert(k, uint64(n), uint64(n))
+	}
+	out := make([]Result, len(queries))
+	b.Run("loop", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for j, k := range queries {
+				out[j].Size, out[j].Offset, out[j].Found = idx.Get(k)
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			idx.GetBatch(queries, out)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package index
+
+import (
+	"math"
+	"math/bits"
+)
+
+// bloom is a standard Bloom filter over key hashes: k bit positions per key
+// derived from one 64-bit hash by double hashing. It never reports a false
+// negative, and bits cannot be cleared, so deleted keys keep matching.
+type bloom struct {
+	bits []uint64
+	m    uint64 // number of bits
+	k    int
+}
+
+// newBloom sizes a filter for n keys at false-positive rate p using the
+// usual m = -n ln p / (ln 2)^2 and k = (m/n) ln 2.
+func newBloom(n int, p float64) *bloom {
+	n = max(1, n)
+	if !(p > 0 && p < 1) {
+		p = 0.01
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	m = max(64, (m+63)/64*64)
+	k := int(math.Round(float64(m) / float64(n) * math.Ln2))
+	return &bloom{bits: make([]uint64, m/64), m: m, k: max(1, k)}
+}
+
+// probe derives the first bit position and the stride from h. The two
+// multipliers decorrelate them from each other and from the table's home
+// slot.
+func probe(h uint64) (a, d uint64) {
+	return h * 0xBF58476D1CE4E5B9, h*0x94D049BB133111EB | 1
+}
+
+func (b *bloom) add(h uint64) {
+	a, d := probe(h)
+	for j := 0; j < b.k; j++ {
+		bit, _ := bits.Mul64(a, b.m)
+		b.bits[bit/64] |= 1 << (bit % 64)
+		a += d
+	}
+}
+
+func (b *bloom) mayContain(h uint64) bool {
+	a, d := probe(h)
+	for j := 0; j < b.k; j++ {
+		bit, _ := bits.Mul64(a, b.m)
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+		a += d
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package index
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestBloomNoFalseNegatives(t *testing.T) {
+	const n = 50000
+	idx := NewWithBloom(n, 0.01)
+	for k := 0; k < n; k++ {
+		idx.Insert("key"+strconv.Itoa(k), uint64(k), 0)
+	}
+	for k := 0; k < n; k++ {
+		if size, _, ok := idx.Get("key" + strconv.Itoa(k)); !ok || size != uint64(k) {
+			t.Fatalf("Get(key%d) = %d, %v", k, size, ok)
+		}
+	}
+
+	fp := 0
+	for k := 0; k < n; k++ {
+		if idx.bloom.mayContain(FNV1a("miss" + strconv.Itoa(k))) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / n; rate > 0.02 {
+		t.Fatalf("false-positive rate %.4f, want about 0.01", rate)
+	}
+
+	// Deletes are not reflected in the filter but Get stays correct.
+	idx.Delete("key0")
+	if _, _, ok := idx.Get("key0"); ok {
+		t.Fatal("deleted key still found")
+	}
+}
+
+// BenchmarkGetMissHeavy compares lookups at a 95% miss rate with and
+// without the Bloom filter.
+func BenchmarkGetMissHeavy(b *testing.B) {
+	blobs, _ := corpus(b, 1000000, 0)
+	queries := make([]string, 0, 100000)
+	for k := 0; k < cap(queries); k++ {
+		if k%20 == 0 {
+			queries = append(queries, blobs[k])
+		} else {
+			queries = append(queries, "miss"+strconv.Itoa(k))
+		}
+	}
+	for _, tc := range []struct {
+		name string
+		idx  *Index
+	}{
+		{"plain", NewWithCapacity(len(blobs))},
+		{"bloom", NewWithBloom(len(blobs), 0.01)},
+	} {
+		for n, k := range blobs {
+			tc.idx.Insert(k, uint64(n), uint64(n))
+		}
+		b.Run(tc.name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				tc.idx.Get(queries[n%len(queries)])
+			}
+		})
+	}
+}
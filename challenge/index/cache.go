@@ -0,0 +1,108 @@
+package index
+
+import (
+	"container/list"
+	"sync"
+)
+
+// NewWithCache returns an empty index whose Get is fronted by an LRU cache
+// of the last size lookups, misses included, for skewed workloads where a
+// few hot keys dominate. Insert and Delete evict the key they touch, so the
+// cache never serves a stale answer. Every Get pays for a locked cache
+// probe, which costs more than it saves on uniform workloads; GetBatch and
+// GetParallel bypass the cache. A size below 1 is treated as 1.
+func NewWithCache(size int, opts ...Option) *Index {
+	return New(append(opts, WithCache(size))...)
+}
+
+// WithCache is the NewWithCache cache as an Option, for constructors such
+// as Reader.ReadIndex that take options but no cache size.
+func WithCache(size int) Option {
+	return func(i *Index) { i.cache = newLRU(max(1, size)) }
+}
+
+// lru is a fixed-size least-recently-used map of lookup results. It is
+// locked so that concurrent Get calls on a frozen index stay safe.
+type lru struct {
+	mu           sync.Mutex
+	size         int
+	order        *list.List // of *lruEntry, most recent first
+	byKey        map[string]*list.Element
+	hits, misses uint64
+}
+
+// lruEntry is one cached Get result.
+type lruEntry struct {
+	key          string
+	size, offset uint64
+	found        bool
+}
+
+// counts returns the cache's hits and misses so far; a nil cache has none.
+func (c *lru) counts() (hits, misses uint64) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+func newLRU(size int) *lru {
+	return &lru{size: size, order: list.New(), byKey: make(map[string]*list.Element, size)}
+}
+
+// get returns the cached result for key, counting the hit or miss.
+func (c *lru) get(key string) (lruEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.byKey[key]
+	if !ok {
+		c.misses++
+		return lruEntry{}, false
+	}
+	c.hits++
+	c.order.MoveToFront(el)
+	return *el.Value.(*lruEntry), true
+}
+
+// put caches e, evicting the least recently used entry when full.
+func (c *lru) put(e lruEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.byKey[e.key]; ok {
+		*el.Value.(*lruEntry) = e
+		c.order.MoveToFront(el)
+		return
+	}
+	if c.order.Len() >= c.size {
+		old := c.order.Back()
+		delete(c.byKey, old.Value.(*lruEntry).key)
+		// Reuse the evicted element's entry for e.
+		*old.Value.(*lruEntry) = e
+		c.order.MoveToFront(old)
+		c.byKey[e.key] = old
+		return
+	}
+	c.byKey[e.key] = c.order.PushFront(&e)
+}
+
+// forget drops key from the cache.
+func (c *lru) forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.byKey[key]; ok {
+		c.order.Remove(el)
+		delete(c.byKey, key)
+	}
+}
+
+// cachedGet is Get through the cache.
+func (i *Index) cachedGet(key string) (size, offset uint64, ok bool) {
+	if e, hit := i.cache.get(key); hit {
+		return e.size, e.offset, e.found
+	}
+	size, offset, ok = i.lookup(key)
+	i.cache.put(lruEntry{key: key, size: size, offset: offset, found: ok})
+	return size, offset, ok
+}
@@ -0,0 +1,76 @@
+package index
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/quadgate/fluxor-blob/challenge/gen"
+)
+
+func TestCache(t *testing.T) {
+	idx := NewWithCache(2)
+	idx.Insert("a", 1, 1)
+	if _, _, ok := idx.Get("b"); ok {
+		t.Fatal("Get(b) found before insert")
+	}
+	// The cached miss must not hide a later insert.
+	idx.Insert("b", 2, 2)
+	if size, _, ok := idx.Get("b"); !ok || size != 2 {
+		t.Fatalf("Get(b) = %d, %v after insert", size, ok)
+	}
+	idx.Insert("b", 3, 3)
+	if size, _, ok := idx.Get("b"); !ok || size != 3 {
+		t.Fatalf("Get(b) = %d, %v after overwrite", size, ok)
+	}
+	idx.Delete("b")
+	if _, _, ok := idx.Get("b"); ok {
+		t.Fatal("Get(b) found after delete")
+	}
+
+	// Fill past the size: a is the least recent and gets evicted.
+	idx.Get("a")
+	idx.Get("c")
+	idx.Get("b")
+	before := idx.Stats()
+	idx.Get("c")
+	idx.Get("a")
+	s := idx.Stats()
+	if hits, misses := s.CacheHits-before.CacheHits, s.CacheMisses-before.CacheMisses; hits != 1 || misses != 1 {
+		t.Fatalf("hits, misses = %d, %d; want 1, 1", hits, misses)
+	}
+	if size, _, ok := idx.Get("a"); !ok || size != 1 {
+		t.Fatalf("Get(a) = %d, %v", size, ok)
+	}
+}
+
+// BenchmarkGetZipf measures Get on a -dist=zipf corpus with and without a
+// 1024-entry cache and reports the cache hit rate.
+func BenchmarkGetZipf(b *testing.B) {
+	cfg := gen.DefaultConfig()
+	cfg.N, cfg.Q, cfg.Dist, cfg.HitRatio = 1000000, 100000, gen.DistZipf, 1
+	var buf bytes.Buffer
+	if err := gen.Generate(&buf, cfg); err != nil {
+		b.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	queries := lines[2+cfg.N:]
+	for _, tt := range []struct {
+		name string
+		idx  *Index
+	}{{"nocache", NewWithCapacity(cfg.N)}, {"cache", NewWithCache(1024)}} {
+		for n, l := range lines[1 : 1+cfg.N] {
+			tt.idx.Insert(l[:strings.IndexByte(l, ' ')], uint64(n), uint64(n))
+		}
+		b.Run(tt.name, func(b *testing.B) {
+			before := tt.idx.Stats()
+			for n := 0; n < b.N; n++ {
+				tt.idx.Get(queries[n%len(queries)])
+			}
+			if s := tt.idx.Stats(); s.CacheHits+s.CacheMisses > before.CacheHits+before.CacheMisses {
+				hits := s.CacheHits - before.CacheHits
+				b.ReportMetric(float64(hits)/float64(hits+s.CacheMisses-before.CacheMisses)*100, "hit-%")
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package index
+
+import (
+	"hash/crc32"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// VerifyAgainst reads each live entry's blob from data, Size bytes at
+// Offset, and returns in lexicographic order the keys whose CRC-32 (IEEE)
+// does not match Entry.Checksum, including blobs that data cannot supply
+// in full. Entries without a checksum are skipped, so a blob whose CRC-32
+// happens to be 0 cannot be verified. Blobs are streamed, so memory use
+// does not depend on their size.
+func (i *Index) VerifyAgainst(data io.ReaderAt) []string {
+	var bad []string
+	h := crc32.NewIEEE()
+	i.eachEntry(func(e Entry) {
+		if e.Checksum == 0 {
+			return
+		}
+		if e.Offset > math.MaxInt64 || e.Size > math.MaxInt64-e.Offset {
+			bad = append(bad, strings.Clone(e.Key))
+			return
+		}
+		h.Reset()
+		n, err := io.Copy(h, io.NewSectionReader(data, int64(e.Offset), int64(e.Size)))
+		if err != nil || uint64(n) != e.Size || h.Sum32() != e.Checksum {
+			bad = append(bad, strings.Clone(e.Key))
+		}
+	})
+	sort.Strings(bad)
+	return bad
+}
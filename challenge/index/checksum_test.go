@@ -0,0 +1,62 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVerifyAgainst(t *testing.T) {
+	data := []byte("hello, world: some blob bytes")
+	sum := func(b []byte) string { return fmt.Sprintf("%08x", crc32.ChecksumIEEE(b)) }
+	in := strings.Join([]string{
+		"6",
+		"good 5 0 " + sum(data[0:5]),
+		"bad 5 7 " + sum(data[0:5]),
+		"none 5 7",
+		"short 10 25 " + sum(data[25:]),
+		"empty 0 3 1",
+		"huge 18446744073709551615 1 1",
+		"0",
+	}, "\n")
+	idx, err := NewReader(strings.NewReader(in)).ReadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"bad", "empty", "huge", "short"} // empty: CRC-32 of nothing is 0, not 1
+	if got := idx.VerifyAgainst(bytes.NewReader(data)); !reflect.DeepEqual(got, want) {
+		t.Fatalf("VerifyAgainst = %q, want %q", got, want)
+	}
+
+	// Checksums survive Save, Load and OpenMmap.
+	var buf bytes.Buffer
+	if err := idx.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "idx")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mapped, err := OpenMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mapped.Close()
+	for _, other := range []*Index{loaded, mapped} {
+		if got := other.VerifyAgainst(bytes.NewReader(data)); !reflect.DeepEqual(got, want) {
+			t.Fatalf("after reload: VerifyAgainst = %q, want %q", got, want)
+		}
+	}
+	if e, _ := mapped.Lookup("good"); e != (Entry{Key: "good", Size: 5, Offset: 0, Checksum: crc32.ChecksumIEEE(data[0:5])}) {
+		t.Fatalf("mapped Lookup(good) = %+v", e)
+	}
+}
@@ -0,0 +1,52 @@
+package index
+
+import "sort"
+
+// Compact rebuilds the record list and key arena with only the live
+// entries, dropping the records left behind by overwrites and deletes and
+// the bytes of deleted keys, and rebuilds the key table to point at the new
+// positions. Live entries keep their insertion order. The bytes freed are
+// added to Stats().ReclaimedBytes. It costs O(entries) time and, while it
+// runs, memory for both the old and the new storage.
+//
+// Compact must not run concurrently with any other method, reads included;
+// it panics on a frozen index. With WithInterning, keys of deleted entries
+// are forgotten. A mapped index is already compact and is left alone.
+func (i *Index) Compact() {
+	i.mustNotBeFrozen("Compact")
+	if i.m != nil {
+		return
+	}
+	if i.shards != nil {
+		for _, s := range i.shards {
+			s.Compact()
+		}
+		return
+	}
+	before := i.memBytes()
+	live := make([]int32, 0, i.count())
+	i.each(func(p int32) { live = append(live, p) })
+	sort.Slice(live, func(a, b int) bool { return live[a] < live[b] })
+
+	old, oldKeys := i.records, i.keys
+	i.records = make([]record, 0, len(live))
+	i.keys = keyArena{}
+	if i.intern != nil {
+		i.intern = map[string]keyRef{}
+	}
+	i.tableInit(len(live))
+	for _, p := range live {
+		r := old[p]
+		display := oldKeys.str(r.key)
+		k := i.norm(display)
+		ref, _ := i.set(k, i.hash(k), int32(len(i.records)))
+		if i.normalize != nil && i.keys.str(ref) != display {
+			ref = i.keys.add(display)
+		}
+		r.key = ref
+		i.records = append(i.records, r)
+	}
+	// Both caches reference old positions or the old arena.
+	i.sorted, i.bySize, i.byOffset = nil, nil, nil
+	i.reclaimed += before - i.memBytes()
+}
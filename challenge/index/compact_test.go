@@ -0,0 +1,60 @@
+package index
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	for _, idx := range []*Index{New(), NewWithSizeIndex(0), New(WithInterning())} {
+		const n = 10000
+		for k := 0; k < n; k++ {
+			idx.Insert(fmt.Sprintf("key%05d", k), uint64(k), 0)
+		}
+		for k := 0; k < n; k++ {
+			switch {
+			case k%2 == 0:
+				idx.Delete(fmt.Sprintf("key%05d", k))
+			case k%3 == 0:
+				idx.Insert(fmt.Sprintf("key%05d", k), uint64(k), 1)
+			}
+		}
+		keys := idx.Keys()
+		top := idx.TopBySize(5)
+		before := idx.Stats()
+
+		idx.Compact()
+		s := idx.Stats()
+		if s.Entries != n/2 || s.Records != n/2 {
+			t.Fatalf("after Compact: Entries, Records = %d, %d; want %d, %d", s.Entries, s.Records, n/2, n/2)
+		}
+		if s.KeyBytes != n/2*8 {
+			t.Fatalf("after Compact: KeyBytes = %d, want %d", s.KeyBytes, n/2*8)
+		}
+		if s.ReclaimedBytes <= 0 || s.ReclaimedBytes != before.MemBytes-s.MemBytes {
+			t.Fatalf("ReclaimedBytes = %d, want MemBytes drop %d", s.ReclaimedBytes, before.MemBytes-s.MemBytes)
+		}
+		for k := 0; k < n; k++ {
+			var wantOffset uint64
+			if k%3 == 0 {
+				wantOffset = 1
+			}
+			size, offset, ok := idx.Get(fmt.Sprintf("key%05d", k))
+			if want := k%2 != 0; ok != want || ok && (size != uint64(k) || offset != wantOffset) {
+				t.Fatalf("Get(key%05d) = %d, %d, %v", k, size, offset, ok)
+			}
+		}
+		if !reflect.DeepEqual(idx.Keys(), keys) || !reflect.DeepEqual(idx.TopBySize(5), top) {
+			t.Fatal("Keys or TopBySize changed across Compact")
+		}
+
+		// The index stays usable, and a second pass frees nothing more.
+		idx.Insert("new", 1, 2)
+		idx.Delete("new")
+		idx.Compact()
+		if s := idx.Stats(); s.Entries != n/2 || s.Records != n/2 {
+			t.Fatalf("second Compact: Entries, Records = %d, %d", s.Entries, s.Records)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ErrNotFound is returned by Content for a key that is not in the index.
+var ErrNotFound = errors.New("index: key not found")
+
+// Content returns the blob stored under key: exactly Size bytes read from
+// src at Offset. It only uses src.ReadAt, so concurrent callers may share
+// one *os.File. A missing key returns ErrNotFound; a blob that src cannot
+// supply in full returns an error wrapping io.ErrUnexpectedEOF (or the
+// read error) and no data. The last byte is read first, so a bogus size
+// beyond the end of src fails before the buffer is allocated.
+func (i *Index) Content(key string, src io.ReaderAt) ([]byte, error) {
+	e, ok := i.Lookup(key)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, key)
+	}
+	if e.Size > math.MaxInt || e.Offset > math.MaxInt64-e.Size {
+		return nil, fmt.Errorf("index: %q: %d bytes at offset %d is out of range", key, e.Size, e.Offset)
+	}
+	if e.Size == 0 {
+		return []byte{}, nil
+	}
+	off, size := int64(e.Offset), int(e.Size)
+	var last [1]byte
+	if _, err := src.ReadAt(last[:], off+int64(size)-1); err != nil {
+		return nil, contentError(key, e, 0, err)
+	}
+	buf := make([]byte, size)
+	n, err := src.ReadAt(buf, off)
+	if n == size {
+		// ReadAt may report io.EOF along with a full read at the end.
+		return buf, nil
+	}
+	return nil, contentError(key, e, n, err)
+}
+
+func contentError(key string, e Entry, n int, err error) error {
+	if err == io.EOF || err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("index: reading %q: got %d of %d bytes at offset %d: %w", key, n, e.Size, e.Offset, err)
+}
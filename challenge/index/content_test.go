@@ -0,0 +1,56 @@
+package index
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestContent(t *testing.T) {
+	data := []byte("0123456789abcdef")
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	idx := New()
+	idx.Insert("digits", 10, 0)
+	idx.Insert("tail", 6, 10)
+	idx.Insert("empty", 0, 100)
+	idx.Insert("past", 4, 14)
+	idx.Insert("huge", 1<<62, 0)
+
+	// Concurrent readers share f.
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, tt := range []struct{ key, want string }{{"digits", "0123456789"}, {"tail", "abcdef"}, {"empty", ""}} {
+				got, err := idx.Content(tt.key, f)
+				if err != nil || string(got) != tt.want {
+					t.Errorf("Content(%q) = %q, %v; want %q", tt.key, got, err, tt.want)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := idx.Content("nope", f); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Content(nope) err = %v, want ErrNotFound", err)
+	}
+	for _, key := range []string{"past", "huge"} {
+		if got, err := idx.Content(key, bytes.NewReader(data)); !errors.Is(err, io.ErrUnexpectedEOF) || got != nil {
+			t.Fatalf("Content(%q) = %q, %v; want io.ErrUnexpectedEOF", key, got, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// endlessBlobs serves an N header followed by blob lines forever, calling
+// cancel once limit lines have been produced.
+type endlessBlobs struct {
+	n, limit int
+	cancel   func()
+	buf      []byte
+}
+
+func (e *endlessBlobs) Read(p []byte) (int, error) {
+	for len(e.buf) < len(p) {
+		e.n++
+		if e.n == e.limit {
+			e.cancel()
+		}
+		e.buf = fmt.Appendf(e.buf, "key%d 1 2\n", e.n)
+	}
+	n := copy(p, e.buf)
+	e.buf = e.buf[n:]
+	return n, nil
+}
+
+func TestBuildFromReaderCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := io.MultiReader(strings.NewReader("2000000000\n"), &endlessBlobs{limit: 10000, cancel: cancel})
+	if _, err := BuildFromReader(ctx, src); !errors.Is(err, context.Canceled) {
+		t.Fatalf("BuildFromReader after cancel: err = %v, want context.Canceled", err)
+	}
+
+	idx, err := BuildFromReader(context.Background(), strings.NewReader("2\nfoo 1 2\nbar 3 4\n1\nfoo\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size, offset, ok := idx.Get("bar"); !ok || size != 3 || offset != 4 {
+		t.Fatalf("Get(bar) = %d, %d, %v", size, offset, ok)
+	}
+}
+
+func TestGetBatchCtx(t *testing.T) {
+	idx := New()
+	idx.Insert("foo", 1, 2)
+	keys := make([]string, 3*ctxCheckEvery)
+	for j := range keys {
+		keys[j] = "foo"
+	}
+	out := make([]Result, len(keys))
+	if err := idx.GetBatchCtx(context.Background(), keys, out); err != nil {
+		t.Fatal(err)
+	}
+	if out[len(out)-1] != (Result{1, 2, true}) {
+		t.Fatalf("last result = %+v", out[len(out)-1])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := idx.GetBatchCtx(ctx, keys, make([]Result, len(keys))); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled GetBatchCtx: err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,80 @@
+package index
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SetDelimited switches the blob section to delimiter-separated rows of
+// "key<comma>size<comma>offset[<comma>checksum]", as exported by
+// spreadsheets and databases: use ',' for CSV and '\t' for TSV. Rows are
+// decoded with encoding/csv, so a double-quoted field may contain the
+// delimiter ("a,b",1,2) or a doubled quote; a quoted field may not span
+// lines. With header set, the first row after the N line is skipped and
+// does not count towards N. Rows are inserts only, without the "+"/"-"
+// opcodes. The count lines and the query section keep the text format.
+func (r *Reader) SetDelimited(comma rune, header bool) {
+	r.row = &rowSource{}
+	r.csv = csv.NewReader(r.row)
+	r.csv.Comma = comma
+	r.csv.FieldsPerRecord = -1 // checked in delimitedBlob for a better message
+	r.csv.ReuseRecord = true
+	r.header = header
+}
+
+// rowSource feeds one input line at a time to the csv.Reader. Once the
+// line is consumed it reports io.EOF; the csv.Reader's buffer keeps no
+// error, so the next line can be fed to the same reader.
+type rowSource struct {
+	buf []byte
+	off int
+}
+
+func (s *rowSource) Read(p []byte) (int, error) {
+	if s.off == len(s.buf) {
+		return 0, io.EOF
+	}
+	n := copy(p, s.buf[s.off:])
+	s.off += n
+	return n, nil
+}
+
+// skipHeader discards the header row, if SetDelimited asked for one.
+func (r *Reader) skipHeader() error {
+	if !r.header {
+		return nil
+	}
+	// At end of input there is nothing to skip; if blobs were expected,
+	// the first one reports the truncation.
+	if _, err := r.next(); err != nil && err != io.ErrUnexpectedEOF {
+		return fmt.Errorf("reading header: %w", err)
+	}
+	return nil
+}
+
+// delimitedBlob parses the current line, whose raw bytes are line, as a
+// delimited row.
+func (r *Reader) delimitedBlob(line []byte) (BlobOp, error) {
+	r.row.buf = append(append(r.row.buf[:0], line...), '\n')
+	r.row.off = 0
+	f, err := r.csv.Read()
+	if err != nil {
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			err = pe.Err
+		}
+		return BlobOp{}, r.errorf("%v", err)
+	}
+	if len(f) != 3 && len(f) != 4 {
+		return BlobOp{}, r.errorf("want key, size, offset[, checksum]; got %d fields", len(f))
+	}
+	var checksum [][]byte
+	if len(f) == 4 {
+		checksum = [][]byte{[]byte(f[3])}
+	}
+	op, err := r.blobFields([]byte(f[1]), []byte(f[2]), checksum)
+	op.Key = f[0]
+	return op, err
+}
@@ -0,0 +1,43 @@
+package index
+
+// DiffResult lists how one index differs from another. All three lists are
+// in lexicographic key order.
+type DiffResult struct {
+	Added    []string // keys only in new
+	Removed  []string // keys only in old
+	Modified []Change // keys in both with a different size, offset or checksum
+}
+
+// Change is a key whose metadata differs between the old and new index.
+// Old.Key and New.Key are the same.
+type Change struct {
+	Old, New Entry
+}
+
+// Diff compares old against new by walking both sorted key sets in step,
+// so it costs the two sortedKeys builds plus one lookup per shared key.
+// Either index may be empty; keys from a mapped index alias its mapping.
+func Diff(old, new *Index) DiffResult {
+	var d DiffResult
+	ok, nk := old.sortedKeys(), new.sortedKeys()
+	for len(ok) > 0 || len(nk) > 0 {
+		switch {
+		case len(nk) == 0 || len(ok) > 0 && ok[0] < nk[0]:
+			d.Removed = append(d.Removed, ok[0])
+			ok = ok[1:]
+		case len(ok) == 0 || nk[0] < ok[0]:
+			d.Added = append(d.Added, nk[0])
+			nk = nk[1:]
+		default:
+			k := ok[0]
+			oe, _ := old.lookupEntry(old.norm(k))
+			ne, _ := new.lookupEntry(new.norm(k))
+			if oe.Size != ne.Size || oe.Offset != ne.Offset || oe.Checksum != ne.Checksum {
+				oe.Key, ne.Key = k, k
+				d.Modified = append(d.Modified, Change{Old: oe, New: ne})
+			}
+			ok, nk = ok[1:], nk[1:]
+		}
+	}
+	return d
+}
@@ -0,0 +1,40 @@
+package index
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old, new := New(), New()
+	for _, k := range []string{"a", "b", "c", "d"} {
+		old.Insert(k, 1, 10)
+	}
+	new.Insert("b", 1, 10) // unchanged
+	new.Insert("c", 1, 11) // offset shifted
+	new.Insert("d", 2, 10) // size changed
+	new.Insert("e", 1, 10) // added; "a" removed
+
+	want := DiffResult{
+		Added:   []string{"e"},
+		Removed: []string{"a"},
+		Modified: []Change{
+			{Old: Entry{Key: "c", Size: 1, Offset: 10}, New: Entry{Key: "c", Size: 1, Offset: 11}},
+			{Old: Entry{Key: "d", Size: 1, Offset: 10}, New: Entry{Key: "d", Size: 2, Offset: 10}},
+		},
+	}
+	if got := Diff(old, new); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Diff = %+v, want %+v", got, want)
+	}
+
+	empty := New()
+	if got := Diff(empty, empty); !reflect.DeepEqual(got, DiffResult{}) {
+		t.Fatalf("Diff(empty, empty) = %+v", got)
+	}
+	if got := Diff(empty, old); !reflect.DeepEqual(got.Added, []string{"a", "b", "c", "d"}) || got.Removed != nil {
+		t.Fatalf("Diff(empty, old) = %+v", got)
+	}
+	if got := Diff(old, empty); len(got.Removed) != 4 || got.Added != nil || got.Modified != nil {
+		t.Fatalf("Diff(old, empty) = %+v", got)
+	}
+}
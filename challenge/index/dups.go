@@ -0,0 +1,53 @@
+package index
+
+import "strings"
+
+// NewWithDupTracking returns an empty index that counts the inserts of
+// every key seen more than once, for spotting upstream data that repeats
+// keys; see Duplicates. Tracking costs a map probe per insert and a map
+// entry and a copy of the key per repeated or deleted key, so it is off
+// in every other constructor. BuildParallel does not track.
+func NewWithDupTracking(opts ...Option) *Index {
+	i := New(opts...)
+	i.dups = map[string]int{}
+	return i
+}
+
+// Duplicates returns each key that was inserted more than once with its
+// insert count, whether the repeats overwrote a live entry or followed a
+// Delete of the key. Keys are reported in WithKeyNormalizer's normal
+// form. The map is the caller's; it is nil unless the index was made by
+// NewWithDupTracking.
+func (i *Index) Duplicates() map[string]int {
+	if i.dups == nil {
+		return nil
+	}
+	out := make(map[string]int)
+	for k, n := range i.dups {
+		if n > 1 {
+			out[k] = n
+		}
+	}
+	return out
+}
+
+// countDup records an insert of the normalized key, which overwrote a live
+// entry for it if existed.
+func (i *Index) countDup(key string, existed bool) {
+	if n, ok := i.dups[key]; ok {
+		i.dups[key] = n + 1
+	} else if existed {
+		// A copy, so the map pins neither the caller's string nor an
+		// arena chunk that Compact replaces.
+		i.dups[strings.Clone(key)] = 2
+	}
+}
+
+// countDelete records a Delete of the live, normalized key, so that a
+// later insert of it counts as a repeat. A key deleted before any repeat
+// is held at one insert, which Duplicates leaves out.
+func (i *Index) countDelete(key string) {
+	if _, ok := i.dups[key]; !ok && i.dups != nil {
+		i.dups[strings.Clone(key)] = 1
+	}
+}
@@ -0,0 +1,70 @@
+package index
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quadgate/fluxor-blob/challenge/gen"
+)
+
+func TestDuplicates(t *testing.T) {
+	const input = `11
+a 1 1
+b 1 1
+a 2 2
+c 1 1
+a 3 3
+- b
+b 2 2
+c 2 2
+d 1 1
+e 1 1
+- e
+`
+	idx := NewWithDupTracking()
+	if err := NewReader(strings.NewReader(input)).ReadBlobs(idx); err != nil {
+		t.Fatal(err)
+	}
+	// b was re-inserted after its delete; e was deleted but never repeated.
+	want := map[string]int{"a": 3, "b": 2, "c": 2}
+	if got := idx.Duplicates(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Duplicates = %v, want %v", got, want)
+	}
+	idx.Insert("c", 3, 3)
+	if got := idx.Duplicates()["c"]; got != 3 {
+		t.Fatalf("after another overwrite: c = %d, want 3", got)
+	}
+	idx.Delete("a")
+	idx.Insert("a", 4, 4)
+	idx.Insert("e", 2, 2)
+	if got := idx.Duplicates(); got["a"] != 4 || got["e"] != 2 {
+		t.Fatalf("after re-inserting deleted keys: a = %d, e = %d; want 4, 2", got["a"], got["e"])
+	}
+
+	if got := New().Duplicates(); got != nil {
+		t.Fatalf("untracked index: Duplicates = %v, want nil", got)
+	}
+}
+
+func TestDuplicatesGenerated(t *testing.T) {
+	// Every gen -dup blob beyond a key's first is one overwrite.
+	cfg := gen.DefaultConfig()
+	cfg.N, cfg.Q, cfg.Dup = 20000, 0, 0.3
+	var in bytes.Buffer
+	if err := gen.Generate(&in, cfg); err != nil {
+		t.Fatal(err)
+	}
+	idx := NewWithDupTracking()
+	if err := NewReader(&in).ReadBlobs(idx); err != nil {
+		t.Fatal(err)
+	}
+	extra := 0
+	for _, n := range idx.Duplicates() {
+		extra += n - 1
+	}
+	if extra == 0 || idx.Len()+extra != cfg.N {
+		t.Fatalf("%d keys + %d repeats, want %d blobs", idx.Len(), extra, cfg.N)
+	}
+}
@@ -0,0 +1,61 @@
+package index
+
+import "encoding/binary"
+
+// Entry is one live key and its metadata, as returned by the query methods
+// that yield more than a single lookup.
+type Entry struct {
+	Key    string
+	Size   uint64
+	Offset uint64
+
+	// Checksum is the CRC-32 (IEEE) of the blob's bytes from the optional
+	// fourth input field, or 0 if none was given; see VerifyAgainst.
+	Checksum uint32
+}
+
+// eachEntry calls fn for every live entry in no particular order (key
+// order for a mapped index), skipping expired InsertWithTTL entries
+// without evicting them.
+func (i *Index) eachEntry(fn func(Entry)) {
+	if len(i.expires) == 0 {
+		i.eachStored(fn)
+		return
+	}
+	i.eachStored(func(e Entry) {
+		if !i.expired(i.norm(e.Key)) {
+			fn(e)
+		}
+	})
+}
+
+// eachStored is eachEntry including expired entries.
+func (i *Index) eachStored(fn func(Entry)) {
+	if i.m != nil {
+		for j := 0; j < i.m.count; j++ {
+			fn(i.m.entry(j))
+		}
+		return
+	}
+	if i.shards != nil {
+		for _, s := range i.shards {
+			s.eachStored(fn)
+		}
+		return
+	}
+	i.each(func(pos int32) {
+		fn(i.records[pos].entry(&i.keys))
+	})
+}
+
+// entry returns r as an Entry whose key views a.
+func (r *record) entry(a *keyArena) Entry {
+	return Entry{Key: a.str(r.key), Size: r.size, Offset: r.offset, Checksum: r.checksum}
+}
+
+// entry returns mapped entry j.
+func (m *mapped) entry(j int) Entry {
+	e := m.entries[j*entrySize:]
+	le := binary.LittleEndian
+	return Entry{Key: m.key(j), Size: le.Uint64(e[16:]), Offset: le.Uint64(e[24:]), Checksum: le.Uint32(e[12:])}
+}
@@ -0,0 +1,92 @@
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+// FuzzParse feeds arbitrary bytes to the reader, in the text format and
+// as CSV, and checks that it returns either an index that answers every
+// parsed query or an error, without panicking.
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"3\nfoo 1 2\n+ bar 3 4\n- foo\n2\nfoo\nbar\n",
+		"1\n\"hello world\" 1 2\n1\n\"hello world\"\n",
+		"2\r\nk\t1\t2\r\n\"\\x00\" 3 4\r\n0\r\n",
+		"",
+		"0\n",
+		"5\nfoo 1 2\n",
+		"1\nfoo 18446744073709551616 0\n",
+		"1\n\"foo 1 2\n",
+		"1\nnospaces\n",
+		"1\nfoo 1 2 deadbeef\n1\nfoo\n",
+		"2000000000\n",
+		"1\n\xff\xfe 1 2\n1\n\xff\xfe\n",
+		"2\nkey,size,offset\n\"a,b\",1,2\n",
+	} {
+		f.Add([]byte(seed), false)
+		f.Add([]byte(seed), true)
+	}
+	f.Fuzz(func(t *testing.T, data []byte, delimited bool) {
+		r := NewReader(bytes.NewReader(data))
+		if delimited {
+			r.SetDelimited(',', len(data)%2 == 0)
+		}
+		idx, err := r.ReadIndex()
+		if err != nil {
+			return
+		}
+		n := 0
+		err = r.ReadQueries(func(key string) error {
+			n++
+			idx.Get(key)
+			return nil
+		})
+		var pe *ParseError
+		if err != nil && !errors.As(err, &pe) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("ReadQueries: unexpected error type %T: %v", err, err)
+		}
+		if got := len(idx.Keys()); got != idx.Len() {
+			t.Fatalf("Keys has %d entries, Len %d", got, idx.Len())
+		}
+	})
+}
+
+// FuzzLoad feeds arbitrary bytes to Load and to the mapped-file parser,
+// whose length fields and key offsets come straight from the input, and
+// checks that every key they report can be looked up without panicking.
+func FuzzLoad(f *testing.F) {
+	idx := New()
+	idx.Insert("foo", 1, 2)
+	idx.Insert("a b", 3, 4)
+	var good bytes.Buffer
+	if err := idx.Save(&good); err != nil {
+		f.Fatal(err)
+	}
+	f.Add(good.Bytes())
+	f.Add(good.Bytes()[:good.Len()-1])
+	f.Add(good.Bytes()[:headerSize])
+	f.Add([]byte{})
+	// A bare header claiming two million empty keys.
+	bogus := append([]byte(nil), good.Bytes()[:headerSize]...)
+	binary.LittleEndian.PutUint64(bogus[8:], 2000000)
+	binary.LittleEndian.PutUint64(bogus[16:], 0)
+	f.Add(bogus)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if idx, err := Load(bytes.NewReader(data)); err == nil {
+			for _, k := range idx.Keys() {
+				if _, _, ok := idx.Get(k); !ok {
+					t.Fatalf("Load: key %q listed but not found", k)
+				}
+			}
+		}
+		if m, err := parseMapped(data); err == nil {
+			for j := 0; j < m.count; j++ {
+				m.get(m.key(j))
+			}
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package index
+
+// hash returns key's hash under the index's hasher (see WithHasher),
+// defaulting to FNV1a.
+func (i *Index) hash(key string) uint64 {
+	if i.hasher != nil {
+		return i.hasher(key)
+	}
+	return FNV1a(key)
+}
+
+// FNV1a is 64-bit FNV-1a, the default hasher, for WithHasher hashers
+// that build on it.
+func FNV1a(key string) uint64 {
+	h := uint64(14695981039346656037)
+	for j := 0; j < len(key); j++ {
+		h ^= uint64(key[j])
+		h *= 1099511628211
+	}
+	return h
+}
@@ -0,0 +1,51 @@
+package index
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Bucket counts the live entries whose size is in [Lo, Hi).
+type Bucket struct {
+	Lo, Hi uint64
+	Count  int
+}
+
+// Histogram is the size distribution SizeHistogram reports.
+type Histogram struct {
+	// Buckets are [0, 1), [1, 2), [2, 4), [4, 8) and so on, in order, up
+	// to the one holding Max; empty buckets in between are kept. The last
+	// possible bucket, [1<<63, MaxUint64), also holds MaxUint64 itself.
+	Buckets []Bucket
+
+	Count    int    // live entries
+	Min, Max uint64 // smallest and largest size; 0 for an empty index
+}
+
+// SizeHistogram buckets the live entries by size into power-of-two ranges
+// in one O(n) pass.
+func (i *Index) SizeHistogram() Histogram {
+	var h Histogram
+	var counts [65]int // indexed by bits.Len64(size)
+	i.eachEntry(func(e Entry) {
+		if h.Count == 0 || e.Size < h.Min {
+			h.Min = e.Size
+		}
+		h.Max = max(h.Max, e.Size)
+		h.Count++
+		counts[bits.Len64(e.Size)]++
+	})
+	if h.Count == 0 {
+		return h
+	}
+	h.Buckets = make([]Bucket, bits.Len64(h.Max)+1)
+	h.Buckets[0] = Bucket{Lo: 0, Hi: 1, Count: counts[0]}
+	for b := 1; b < len(h.Buckets); b++ {
+		hi := uint64(math.MaxUint64)
+		if b < 64 {
+			hi = 1 << b
+		}
+		h.Buckets[b] = Bucket{Lo: 1 << (b - 1), Hi: hi, Count: counts[b]}
+	}
+	return h
+}
@@ -0,0 +1,35 @@
+package index
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSizeHistogram(t *testing.T) {
+	if h := New().SizeHistogram(); !reflect.DeepEqual(h, Histogram{}) {
+		t.Fatalf("empty index: %+v", h)
+	}
+
+	idx := New()
+	for j, size := range []uint64{0, 1, 2, 3, 3, 9, 12} {
+		idx.Insert(string(rune('a'+j)), size, 0)
+	}
+	idx.Insert("a", 5, 0) // overwrites size 0
+	idx.Delete("e")
+	want := Histogram{
+		Buckets: []Bucket{{0, 1, 0}, {1, 2, 1}, {2, 4, 2}, {4, 8, 1}, {8, 16, 2}},
+		Count:   6,
+		Min:     1,
+		Max:     12,
+	}
+	if h := idx.SizeHistogram(); !reflect.DeepEqual(h, want) {
+		t.Fatalf("SizeHistogram = %+v, want %+v", h, want)
+	}
+
+	idx.Insert("max", math.MaxUint64, 0)
+	h := idx.SizeHistogram()
+	if last := h.Buckets[len(h.Buckets)-1]; len(h.Buckets) != 65 || last != (Bucket{1 << 63, math.MaxUint64, 1}) {
+		t.Fatalf("with MaxUint64: %d buckets, last %+v", len(h.Buckets), last)
+	}
+}
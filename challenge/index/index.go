@@ -0,0 +1,306 @@
+// Package index is an in-memory key -> (size, offset) index for the Fast
+// Blob Indexer challenge.
+//
+// The key table is an open-addressing Robin Hood hash table keyed by
+// precomputed FNV-1a hashes (table_robinhood.go). Building with
+// -tags stdmap swaps in the built-in map instead (table_stdmap.go), for
+// comparison.
+package index
+
+import (
+	"slices"
+	"time"
+)
+
+// record is one inserted blob. Records are append-only; an overwrite
+// appends a new record, sharing the key's arena bytes, and repoints the key
+// at it, and a delete only drops the key, leaving the old record
+// unreferenced.
+type record struct {
+	key      keyRef
+	checksum uint32 // Entry.Checksum; fills what would be padding
+	size     uint64
+	offset   uint64
+}
+
+// Index maps blob keys to their size and offset.
+type Index struct {
+	t       table // key -> position in records
+	records []record
+	keys    keyArena          // bytes of every key in records
+	intern  map[string]keyRef // WithInterning: every key ever added to keys
+
+	sorted   []string // lazily built sorted key set; nil when stale
+	bySize   []int32  // lazily built size order of live records; see NewWithSizeIndex
+	sizeIx   bool     // maintain bySize
+	byOffset []int32  // lazily built offset order of live records; see NewWithOffsetIndex
+	offsetIx bool     // maintain byOffset
+	bloom    *bloom   // optional negative-lookup filter; see NewWithBloom
+	frozen   bool     // set by Freeze; Insert and Delete panic afterwards
+	m        *mapped  // set by OpenMmap; lookups then bypass t and records
+	shards   []*Index // set by BuildParallel; keys then live in shardOf(hash)
+	cache    *lru     // optional recent-lookup cache; see NewWithCache
+
+	reclaimed int64 // bytes freed by Compact so far
+
+	expires map[string]time.Time // normalized key -> deadline; see InsertWithTTL
+	now     func() time.Time     // nil means time.Now; see WithClock
+
+	maxMemory int64 // build cap in bytes; 0 means none. See WithMaxMemory
+
+	dups map[string]int // overwritten key -> inserts; see NewWithDupTracking
+
+	// normalize maps keys to their table form; nil means as is. Records
+	// keep the key as inserted. See WithKeyNormalizer.
+	normalize func(key string) string
+
+	hasher func(key string) uint64 // nil means FNV1a; see WithHasher
+}
+
+// maxCapacityHint caps NewWithCapacity's pre-allocation so a bogus count
+// cannot exhaust memory up front. It covers the standard 1M-blob input;
+// larger indexes still grow on demand.
+const maxCapacityHint = 1 << 21
+
+// inputCapacityHint caps the pre-size taken from a count read off the
+// input, a Load header or an N line, which no data backs yet: a few bytes
+// claiming two million entries must not reserve their slots before a
+// single entry has been read. Once that many entries have arrived, the
+// builder reserves eight times as many (see reserve), up to the count, so
+// what it reserves stays within a constant factor of the input consumed.
+const inputCapacityHint = 1 << 16
+
+// New returns an empty index configured by opts.
+func New(opts ...Option) *Index {
+	return NewWithCapacity(0, opts...)
+}
+
+// NewWithCapacity returns an empty index pre-sized for n entries, so
+// building it from a known count does not rehash as it grows. Negative n is
+// treated as 0 and very large n is capped, below the WithMaxMemory cap if
+// one is set; n is only a hint.
+func NewWithCapacity(n int, opts ...Option) *Index {
+	n = max(0, min(n, maxCapacityHint))
+	i := &Index{}
+	for _, o := range opts {
+		o(i)
+	}
+	for i.maxMemory > 0 && n > 0 && EstimateMemory(n, 0) > i.maxMemory {
+		n /= 2
+	}
+	i.records = make([]record, 0, n)
+	i.tableInit(n)
+	return i
+}
+
+// reserve extends the NewWithCapacity pre-size of a heap index to n
+// entries, under the same caps.
+func (i *Index) reserve(n int) {
+	if i.m != nil || i.shards != nil {
+		return
+	}
+	n = min(n, maxCapacityHint)
+	for i.maxMemory > 0 && n > 0 && EstimateMemory(n, 0) > i.maxMemory {
+		n /= 2
+	}
+	if n > cap(i.records) {
+		i.records = slices.Grow(i.records, n-len(i.records))
+	}
+	i.tableReserve(n)
+}
+
+// NewWithBloom is NewWithCapacity(n) plus a Bloom filter sized for n keys
+// at false-positive rate fpRate (0.01 if out of range). Get consults the
+// filter first, so most misses skip the table probe, which pays off on
+// miss-heavy workloads.
+//
+// The filter cannot forget keys: Delete still works, but deleted keys keep
+// costing a full probe, and inserting well beyond n raises the
+// false-positive rate. It is meant for build-once, delete-free indexes.
+func NewWithBloom(n int, fpRate float64, opts ...Option) *Index {
+	i := NewWithCapacity(n, opts...)
+	i.bloom = newBloom(max(0, min(n, maxCapacityHint)), fpRate)
+	return i
+}
+
+// Insert stores size and offset under key. A repeated key overwrites the
+// existing entry: the most recent insert wins.
+func (i *Index) Insert(key string, size, offset uint64) {
+	i.InsertEntry(Entry{Key: key, Size: size, Offset: offset})
+}
+
+// InsertEntry is Insert of e.Key, keeping e.Checksum as well.
+func (i *Index) InsertEntry(e Entry) {
+	i.mustNotBeFrozen("Insert")
+	nk := i.norm(e.Key)
+	if i.cache != nil {
+		i.cache.forget(nk)
+	}
+	if i.expires != nil {
+		delete(i.expires, nk)
+	}
+	i.insertHashed(nk, i.hash(nk), e)
+}
+
+// insertHashed is InsertEntry of e, whose key's normalized form is key
+// with hash h, both already computed.
+func (i *Index) insertHashed(key string, h uint64, e Entry) {
+	if i.shards != nil {
+		// The shard's Len tells whether key was new; only then does the
+		// sorted key set go stale, unless the display form may change.
+		s := i.shardOf(h)
+		n := s.count()
+		s.insertHashed(key, h, e)
+		if s.count() != n || i.normalize != nil {
+			i.sorted = nil
+		}
+		if i.dups != nil {
+			i.countDup(key, s.count() == n)
+		}
+		return
+	}
+	ref, existed := i.set(key, h, int32(len(i.records)))
+	if !existed || i.normalize != nil {
+		i.sorted = nil
+	}
+	if i.dups != nil {
+		i.countDup(key, existed)
+	}
+	i.bySize, i.byOffset = nil, nil
+	if i.bloom != nil {
+		i.bloom.add(h)
+	}
+	// ref holds key, or with -tags stdmap possibly an earlier display
+	// form; either way it can be shared when it matches display.
+	if i.normalize != nil && i.keys.str(ref) != e.Key {
+		ref = i.keys.add(e.Key)
+	}
+	i.records = append(i.records, record{key: ref, checksum: e.Checksum, size: e.Size, offset: e.Offset})
+}
+
+// Delete removes key from the index and reports whether it was present.
+// Deleting an absent key is a no-op.
+func (i *Index) Delete(key string) bool {
+	i.mustNotBeFrozen("Delete")
+	key = i.norm(key)
+	if i.cache != nil {
+		i.cache.forget(key)
+	}
+	if i.expires != nil {
+		delete(i.expires, key)
+	}
+	if i.shards != nil {
+		if !i.shardOf(i.hash(key)).Delete(key) {
+			return false
+		}
+		i.sorted = nil
+		i.countDelete(key)
+		return true
+	}
+	if !i.unset(key) {
+		return false
+	}
+	i.sorted = nil
+	i.countDelete(key)
+	i.bySize, i.byOffset = nil, nil
+	return true
+}
+
+// Get returns the size and offset stored under key. ok is false if key is
+// not present or has expired.
+func (i *Index) Get(key string) (size, offset uint64, ok bool) {
+	key = i.norm(key)
+	if i.expired(key) {
+		i.evict(key)
+		return 0, 0, false
+	}
+	if i.cache != nil {
+		return i.cachedGet(key)
+	}
+	return i.lookup(key)
+}
+
+// Lookup returns key's full entry, including its checksum, with the key as
+// stored. Unlike Get it bypasses the NewWithCache cache, and it reports
+// an expired key as absent without evicting it.
+func (i *Index) Lookup(key string) (Entry, bool) {
+	key = i.norm(key)
+	if i.expired(key) {
+		return Entry{}, false
+	}
+	return i.lookupEntry(key)
+}
+
+// lookupEntry is Lookup of a normalized key, expired or not.
+func (i *Index) lookupEntry(key string) (Entry, bool) {
+	if i.m != nil {
+		j, ok := i.m.find(key)
+		if !ok {
+			return Entry{}, false
+		}
+		return i.m.entry(j), true
+	}
+	if i.shards != nil {
+		return i.shardOf(i.hash(key)).lookupEntry(key)
+	}
+	p, ok := i.find(key)
+	if !ok {
+		return Entry{}, false
+	}
+	return i.records[p].entry(&i.keys), true
+}
+
+// lookup is Get of a normalized key without the cache.
+func (i *Index) lookup(key string) (size, offset uint64, ok bool) {
+	if i.m != nil {
+		return i.m.get(key)
+	}
+	if i.shards != nil {
+		h := i.hash(key)
+		return i.shardOf(h).getHashed(key, h)
+	}
+	var p int32
+	if i.bloom != nil {
+		h := i.hash(key)
+		if !i.bloom.mayContain(h) {
+			return 0, 0, false
+		}
+		p, ok = i.findHashed(key, h)
+	} else {
+		p, ok = i.find(key)
+	}
+	if !ok {
+		return 0, 0, false
+	}
+	r := &i.records[p]
+	return r.size, r.offset, true
+}
+
+// getHashed is Get on an unsharded, unmapped index with key's hash h
+// already computed.
+func (i *Index) getHashed(key string, h uint64) (size, offset uint64, ok bool) {
+	if i.bloom != nil && !i.bloom.mayContain(h) {
+		return 0, 0, false
+	}
+	p, ok := i.findHashed(key, h)
+	if !ok {
+		return 0, 0, false
+	}
+	r := &i.records[p]
+	return r.size, r.offset, true
+}
+
+// Len returns the number of distinct keys in the index.
+func (i *Index) Len() int {
+	if i.m != nil {
+		return i.m.count
+	}
+	if i.shards != nil {
+		n := 0
+		for _, s := range i.shards {
+			n += s.count()
+		}
+		return n
+	}
+	return i.count()
+}
@@ -0,0 +1,164 @@
+package index
+
+import (
+	"bytes"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/quadgate/fluxor-blob/challenge/gen"
+)
+
+func TestInsertGet(t *testing.T) {
+	idx := New()
+	idx.Insert("avatar123", 102400, 0)
+	idx.Insert("thumbnail", 8192, 614400)
+
+	if size, offset, ok := idx.Get("avatar123"); !ok || size != 102400 || offset != 0 {
+		t.Fatalf("Get(avatar123) = %d, %d, %v", size, offset, ok)
+	}
+	if size, offset, ok := idx.Get("thumbnail"); !ok || size != 8192 || offset != 614400 {
+		t.Fatalf("Get(thumbnail) = %d, %d, %v", size, offset, ok)
+	}
+	if idx.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", idx.Len())
+	}
+}
+
+func TestInsert64Bit(t *testing.T) {
+	idx := New()
+	idx.Insert("big", 6_000_000_000, 5_000_000_000)
+	size, offset, ok := idx.Get("big")
+	if !ok || size != 6_000_000_000 || offset != 5_000_000_000 {
+		t.Fatalf("Get(big) = %d, %d, %v", size, offset, ok)
+	}
+}
+
+func TestGetMissing(t *testing.T) {
+	idx := New()
+	if _, _, ok := idx.Get("not_exist"); ok {
+		t.Fatal("Get on empty index reported ok")
+	}
+	idx.Insert("", 0, 0)
+	if _, _, ok := idx.Get(""); !ok {
+		t.Fatal("zero-valued entry for empty key not found")
+	}
+}
+
+func TestInsertOverwrite(t *testing.T) {
+	idx := New()
+	idx.Insert("foo", 1, 1)
+	idx.Insert("foo", 2, 2)
+
+	size, offset, ok := idx.Get("foo")
+	if !ok || size != 2 || offset != 2 {
+		t.Fatalf("Get(foo) = %d, %d, %v; want 2, 2, true", size, offset, ok)
+	}
+	if idx.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", idx.Len())
+	}
+}
+
+func TestDelete(t *testing.T) {
+	idx := New()
+	idx.Insert("foo", 1, 1)
+	if !idx.Delete("foo") {
+		t.Fatal("Delete(foo) = false, want true")
+	}
+	if _, _, ok := idx.Get("foo"); ok {
+		t.Fatal("deleted key still found")
+	}
+	if idx.Delete("foo") {
+		t.Fatal("second Delete(foo) = true, want false")
+	}
+	idx.Insert("foo", 3, 3)
+	if size, _, ok := idx.Get("foo"); !ok || size != 3 {
+		t.Fatalf("re-inserted Get(foo) = %d, %v", size, ok)
+	}
+}
+
+func TestNewWithCapacity(t *testing.T) {
+	for _, n := range []int{-1, 0, 10, 1 << 62} {
+		idx := NewWithCapacity(n)
+		idx.Insert("foo", 1, 2)
+		if size, offset, ok := idx.Get("foo"); !ok || size != 1 || offset != 2 {
+			t.Fatalf("NewWithCapacity(%d): Get(foo) = %d, %d, %v", n, size, offset, ok)
+		}
+	}
+}
+
+// TestAgainstMap drives random inserts, overwrites and deletes against a
+// reference map to exercise probing, growth and backward-shift deletion.
+func TestAgainstMap(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	idx := New()
+	ref := make(map[string]uint64)
+	for n := 0; n < 200000; n++ {
+		key := strconv.Itoa(rng.Intn(5000))
+		if rng.Intn(3) == 0 {
+			_, want := ref[key]
+			delete(ref, key)
+			if got := idx.Delete(key); got != want {
+				t.Fatalf("op %d: Delete(%s) = %v, want %v", n, key, got, want)
+			}
+			continue
+		}
+		v := uint64(n)
+		ref[key] = v
+		idx.Insert(key, v, v)
+	}
+	if idx.Len() != len(ref) {
+		t.Fatalf("Len() = %d, want %d", idx.Len(), len(ref))
+	}
+	for k := 0; k < 5000; k++ {
+		key := strconv.Itoa(k)
+		want, wantOK := ref[key]
+		size, _, ok := idx.Get(key)
+		if ok != wantOK || size != want {
+			t.Fatalf("Get(%s) = %d, %v; want %d, %v", key, size, ok, want, wantOK)
+		}
+	}
+}
+
+// corpus returns the stored and query keys of a generated input.
+func corpus(tb testing.TB, n, q int) (blobs, queries []string) {
+	cfg := gen.DefaultConfig()
+	cfg.N, cfg.Q = n, q
+	var buf bytes.Buffer
+	if err := gen.Generate(&buf, cfg); err != nil {
+		tb.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for _, l := range lines[1 : 1+n] {
+		blobs = append(blobs, l[:strings.IndexByte(l, ' ')])
+	}
+	return blobs, lines[2+n:]
+}
+
+// BenchmarkGet measures lookups on a generated corpus (50% hits). Run with
+// -tags stdmap to measure the built-in map backend instead.
+func BenchmarkGet(b *testing.B) {
+	blobs, queries := corpus(b, 1000000, 100000)
+	idx := NewWithCapacity(len(blobs))
+	for n, k := range blobs {
+		idx.Insert(k, uint64(n), uint64(n))
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		idx.Get(queries[n%len(queries)])
+	}
+}
+
+// BenchmarkGetBuiltinMap is the stdlib-map baseline for BenchmarkGet.
+func BenchmarkGetBuiltinMap(b *testing.B) {
+	blobs, queries := corpus(b, 1000000, 100000)
+	m := make(map[string]int32, len(blobs))
+	for n, k := range blobs {
+		m[k] = int32(n)
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_ = m[queries[n%len(queries)]]
+	}
+}
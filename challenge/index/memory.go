@@ -0,0 +1,51 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// ErrMemoryLimit is returned by a build whose estimated memory use exceeds
+// the WithMaxMemory cap.
+var ErrMemoryLimit = errors.New("index: memory limit exceeded")
+
+// EstimateMemory returns the approximate bytes an index of n distinct keys
+// averaging avgKeyLen bytes holds once built from a pre-sized count: the
+// records, the key bytes and the hash table, as Stats().MemBytes counts
+// them minus the unused tail of the last arena chunk. n is capped at the
+// largest count Reader accepts and avgKeyLen at MaxLineSize.
+func EstimateMemory(n, avgKeyLen int) int64 {
+	n = max(0, min(n, 1<<31-1))
+	avgKeyLen = max(0, min(avgKeyLen, MaxLineSize))
+	return int64(n)*(int64(unsafe.Sizeof(record{}))+int64(avgKeyLen)) + tableBytesFor(n)
+}
+
+// WithMaxMemory caps the memory a build from a Reader, BuildFromReader or
+// BuildParallel may take at about bytes, as EstimateMemory reckons it from
+// the blob count N. The build fails with ErrMemoryLimit before allocating
+// if N alone is over the cap, and again while reading, every few thousand
+// blobs, once N at the average key length seen so far would be; that
+// catches inputs whose keys are far longer than their count suggests.
+// Overwrites and deletes are counted as if every blob were a new key.
+// NewWithCapacity also shrinks its pre-allocation to fit the cap. Direct
+// Insert calls are not checked. bytes <= 0 means no cap.
+func WithMaxMemory(bytes int64) Option {
+	return func(i *Index) { i.maxMemory = bytes }
+}
+
+// checkMemory returns an ErrMemoryLimit error if a build of n blobs whose
+// first b keys came to keyBytes would go over the WithMaxMemory cap.
+func (i *Index) checkMemory(n, b int, keyBytes int64) error {
+	if i.maxMemory <= 0 {
+		return nil
+	}
+	avg := 0
+	if b > 0 {
+		avg = int(min((keyBytes+int64(b)-1)/int64(b), MaxLineSize))
+	}
+	if est := EstimateMemory(n, avg); est > i.maxMemory {
+		return fmt.Errorf("%w: %d blobs of %d-byte keys need about %d bytes, cap is %d", ErrMemoryLimit, n, avg, est, i.maxMemory)
+	}
+	return nil
+}
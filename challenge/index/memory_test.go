@@ -0,0 +1,63 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEstimateMemory(t *testing.T) {
+	const n, keyLen = 100000, 16
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d\n", n)
+	for j := 0; j < n; j++ {
+		fmt.Fprintf(&b, "%016d 1 2\n", j)
+	}
+	idx, err := NewReader(strings.NewReader(b.String())).ReadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	est, got := EstimateMemory(n, keyLen), idx.Stats().MemBytes
+	// MemBytes also counts the unused tail of the last arena chunk.
+	if est > got || got-est > arenaChunk {
+		t.Fatalf("EstimateMemory = %d, MemBytes = %d", est, got)
+	}
+	if EstimateMemory(-1, -1) != EstimateMemory(0, 0) {
+		t.Fatal("negative arguments not treated as 0")
+	}
+}
+
+func TestWithMaxMemory(t *testing.T) {
+	input := func(n, keyLen int) string {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d\n", n)
+		for j := 0; j < n; j++ {
+			fmt.Fprintf(&b, "%0*d 1 2\n", keyLen, j)
+		}
+		return b.String()
+	}
+	limit := EstimateMemory(10000, 16)
+
+	if _, err := NewReader(strings.NewReader(input(10000, 16))).ReadIndex(WithMaxMemory(limit)); err != nil {
+		t.Fatalf("build at the cap: %v", err)
+	}
+	// A count far over the cap fails before any blob is read.
+	if _, err := BuildFromReader(context.Background(), strings.NewReader("500000000\n"), WithMaxMemory(limit)); !errors.Is(err, ErrMemoryLimit) {
+		t.Fatalf("huge N: err = %v, want ErrMemoryLimit", err)
+	}
+	// A count that fits but keys 64 times longer than planned fail
+	// partway through.
+	if _, err := NewReader(strings.NewReader(input(10000, 1024))).ReadIndex(WithMaxMemory(limit)); !errors.Is(err, ErrMemoryLimit) {
+		t.Fatalf("long keys: err = %v, want ErrMemoryLimit", err)
+	}
+	if _, err := BuildParallel(context.Background(), strings.NewReader(input(10000, 1024)), 2, WithMaxMemory(limit)); !errors.Is(err, ErrMemoryLimit) {
+		t.Fatalf("BuildParallel long keys: err = %v, want ErrMemoryLimit", err)
+	}
+
+	// The pre-allocation itself stays under the cap.
+	if got := NewWithCapacity(1<<20, WithMaxMemory(limit)).Stats().MemBytes; got > limit {
+		t.Fatalf("NewWithCapacity pre-allocated %d bytes, cap %d", got, limit)
+	}
+}
@@ -0,0 +1,28 @@
+package index
+
+import "strings"
+
+// Merge inserts every live entry of other into i. On a key present in
+// both, other's size and offset win, exactly as if other's entries were
+// inserted after i's; keys other deleted are left alone in i. Merging
+// shards in a fixed order therefore gives a deterministic result. Entries
+// expired in other are skipped, and the rest keep their InsertWithTTL
+// deadlines. other is not modified and may be frozen or mapped; i must
+// not be frozen.
+func (i *Index) Merge(other *Index) {
+	i.mustNotBeFrozen("Merge")
+	if other == i {
+		return
+	}
+	mapped := other.m != nil
+	other.eachEntry(func(e Entry) {
+		if mapped {
+			// Mapped keys alias other's file mapping; i must outlive Close.
+			e.Key = strings.Clone(e.Key)
+		}
+		i.InsertEntry(e)
+		if d, has := other.expires[other.norm(e.Key)]; has {
+			i.setDeadline(e.Key, d)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package index
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	a, b := New(), New()
+	a.Insert("only-a", 1, 1)
+	a.Insert("both", 2, 2)
+	a.Insert("gone", 9, 9)
+	b.Insert("both", 3, 3)
+	b.Insert("only-b", 4, 4)
+	b.Insert("gone", 5, 5)
+	b.Delete("gone") // b's delete does not propagate
+	b.Freeze()
+
+	a.Merge(b)
+	want := map[string][2]uint64{"only-a": {1, 1}, "both": {3, 3}, "only-b": {4, 4}, "gone": {9, 9}}
+	if a.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", a.Len(), len(want))
+	}
+	for k, w := range want {
+		if size, offset, ok := a.Get(k); !ok || size != w[0] || offset != w[1] {
+			t.Errorf("Get(%s) = %d, %d, %v; want %d, %d", k, size, offset, ok, w[0], w[1])
+		}
+	}
+	if b.Len() != 2 {
+		t.Fatalf("Merge modified other: Len() = %d", b.Len())
+	}
+
+	a.Merge(a)
+	if a.Len() != len(want) {
+		t.Fatalf("self-merge changed Len() to %d", a.Len())
+	}
+	a.Merge(New())
+	New().Merge(a)
+
+	// Keys merged from a mapped index must survive its Close.
+	path, _ := saveFile(t, a)
+	m, err := OpenMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New()
+	c.Merge(m)
+	m.Close()
+	if got := c.Keys(); len(got) != 4 || got[0] != "both" {
+		t.Fatalf("keys merged from mapped index = %q", got)
+	}
+}
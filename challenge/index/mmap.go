@@ -0,0 +1,120 @@
+package index
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+	"unsafe"
+)
+
+// mapped is a saved index file read in place. Entries are sorted by key,
+// so lookups binary-search the mapping directly and nothing is copied onto
+// the heap; pages are faulted in as lookups touch them.
+type mapped struct {
+	data    []byte // whole file
+	arena   []byte
+	entries []byte
+	count   int
+	release func([]byte) error // unmaps data; nil for heap-backed data
+}
+
+// OpenMmap opens an index file written by Save and serves lookups straight
+// from a read-only memory mapping of it (a plain read of the file on
+// platforms without mmap). The header and the file length are checked up
+// front, so a truncated or foreign file fails with ErrBadFormat instead of
+// faulting later; entries pointing outside the key arena read as empty
+// keys rather than crashing.
+//
+// The returned index is frozen. Keys it hands out, such as PrefixScan
+// results, alias the mapping and are only valid until Close. The file must
+// not be truncated while it is mapped.
+func OpenMmap(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, release, err := mapFile(f)
+	if err != nil {
+		return nil, err
+	}
+	m, err := parseMapped(data)
+	if err != nil {
+		if release != nil {
+			release(data)
+		}
+		return nil, err
+	}
+	m.release = release
+	i := New()
+	i.m = m
+	i.Freeze()
+	return i, nil
+}
+
+// Close releases the mapping behind an index opened with OpenMmap. The
+// index must not be used afterwards. Close on any other index is a no-op.
+func (i *Index) Close() error {
+	m := i.m
+	if m == nil {
+		return nil
+	}
+	i.m = nil
+	i.sorted = nil
+	if m.release == nil {
+		return nil
+	}
+	return m.release(m.data)
+}
+
+func parseMapped(data []byte) (*mapped, error) {
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("%w: %d bytes is shorter than the header", ErrBadFormat, len(data))
+	}
+	if string(data[:4]) != fileMagic {
+		return nil, fmt.Errorf("%w: bad magic %q", ErrBadFormat, data[:4])
+	}
+	le := binary.LittleEndian
+	if v := le.Uint32(data[4:]); v != fileVersion {
+		return nil, fmt.Errorf("%w: version %d, want %d", ErrBadFormat, v, fileVersion)
+	}
+	count, keyBytes := le.Uint64(data[8:]), le.Uint64(data[16:])
+	body := uint64(len(data) - headerSize)
+	if keyBytes > body || count > (body-keyBytes)/entrySize || headerSize+keyBytes+count*entrySize != uint64(len(data)) {
+		return nil, fmt.Errorf("%w: %d bytes does not hold %d keys and %d entries", ErrBadFormat, len(data), keyBytes, count)
+	}
+	arenaEnd := headerSize + int(keyBytes)
+	return &mapped{
+		data:    data,
+		arena:   data[headerSize:arenaEnd],
+		entries: data[arenaEnd:],
+		count:   int(count),
+	}, nil
+}
+
+// key returns entry j's key without copying, or "" if the entry points
+// outside the arena.
+func (m *mapped) key(j int) string {
+	e := m.entries[j*entrySize:]
+	off, klen := binary.LittleEndian.Uint64(e), uint64(binary.LittleEndian.Uint32(e[8:]))
+	if klen == 0 || off > uint64(len(m.arena)) || klen > uint64(len(m.arena))-off {
+		return ""
+	}
+	return unsafe.String(&m.arena[off], int(klen))
+}
+
+func (m *mapped) get(key string) (size, offset uint64, ok bool) {
+	j, ok := m.find(key)
+	if !ok {
+		return 0, 0, false
+	}
+	e := m.entry(j)
+	return e.Size, e.Offset, true
+}
+
+// find binary-searches the sorted entries for key.
+func (m *mapped) find(key string) (int, bool) {
+	j := sort.Search(m.count, func(j int) bool { return m.key(j) >= key })
+	return j, j < m.count && m.key(j) == key
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package index
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads the whole file where mmap is unavailable.
+func mapFile(f *os.File) ([]byte, func([]byte) error, error) {
+	data, err := io.ReadAll(f)
+	return data, nil, err
+}
@@ -0,0 +1,88 @@
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func saveFile(t *testing.T, idx *Index) (string, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := idx.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "idx.bin")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path, buf.Bytes()
+}
+
+func TestOpenMmap(t *testing.T) {
+	blobs, queries := corpus(t, 5000, 2000)
+	idx := NewWithCapacity(len(blobs))
+	for n, k := range blobs {
+		idx.Insert(k, uint64(n), uint64(n*7))
+	}
+	path, _ := saveFile(t, idx)
+
+	m, err := OpenMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if m.Len() != idx.Len() || !m.Frozen() {
+		t.Fatalf("Len() = %d, Frozen() = %v", m.Len(), m.Frozen())
+	}
+	for _, k := range append(queries, blobs...) {
+		s1, o1, ok1 := idx.Get(k)
+		s2, o2, ok2 := m.Get(k)
+		if s1 != s2 || o1 != o2 || ok1 != ok2 {
+			t.Fatalf("Get(%s) = %d %d %v mapped, want %d %d %v", k, s2, o2, ok2, s1, o1, ok1)
+		}
+	}
+	if got, want := m.PrefixScan("ab"), idx.PrefixScan("ab"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("PrefixScan(ab) = %v, want %v", got, want)
+	}
+}
+
+func TestOpenMmapCorrupt(t *testing.T) {
+	idx := New()
+	idx.Insert("foo", 1, 2)
+	idx.Insert("bar", 3, 4)
+	path, good := saveFile(t, idx)
+
+	if err := os.WriteFile(path, good[:len(good)-1], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenMmap(path); !errors.Is(err, ErrBadFormat) {
+		t.Fatalf("truncated: err = %v, want ErrBadFormat", err)
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenMmap(path); !errors.Is(err, ErrBadFormat) {
+		t.Fatalf("empty: err = %v, want ErrBadFormat", err)
+	}
+
+	// Point the first entry's key far outside the arena: lookups must
+	// miss, not crash.
+	bad := append([]byte(nil), good...)
+	binary.LittleEndian.PutUint64(bad[headerSize+6:], 1<<40)
+	if err := os.WriteFile(path, bad, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	m.Get("bar")
+	m.Get("foo")
+	m.PrefixScan("")
+}
@@ -0,0 +1,31 @@
+package index
+
+import "strings"
+
+// WithKeyNormalizer makes every Insert, Delete and lookup go through f
+// first, so keys with the same normal form are the same entry: the last
+// insert wins and its key, as given, is the one Keys and the other key
+// listings report. f must be deterministic and idempotent (f(f(k)) ==
+// f(k)). Inserting a key that f changes stores both forms.
+//
+// Ordered queries (PrefixScan, Ceiling, Floor) compare the reported keys
+// and so stay case-sensitive, and Save writes them as reported; Load and
+// OpenMmap build indexes without a normalizer.
+func WithKeyNormalizer(f func(key string) string) Option {
+	return func(i *Index) { i.normalize = f }
+}
+
+// NewCaseInsensitive returns an empty index whose keys match regardless of
+// case: WithKeyNormalizer(strings.ToLower), so "Foo" and "foo" are one
+// entry.
+func NewCaseInsensitive(opts ...Option) *Index {
+	return New(append([]Option{WithKeyNormalizer(strings.ToLower)}, opts...)...)
+}
+
+// norm returns key's table form.
+func (i *Index) norm(key string) string {
+	if i.normalize == nil {
+		return key
+	}
+	return i.normalize(key)
+}
@@ -0,0 +1,67 @@
+package index
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCaseInsensitive(t *testing.T) {
+	idx := NewCaseInsensitive()
+	idx.Insert("Foo", 1, 1)
+	idx.Insert("foo", 2, 2) // same entry; last write wins
+	idx.Insert("BAR", 3, 3)
+	idx.Insert("Baz", 4, 4)
+	idx.Delete("bAZ")
+
+	if idx.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", idx.Len())
+	}
+	for _, k := range []string{"foo", "FOO", "fOo"} {
+		if size, _, ok := idx.Get(k); !ok || size != 2 {
+			t.Fatalf("Get(%q) = %d, %v; want 2, true", k, size, ok)
+		}
+	}
+	if _, _, ok := idx.Get("baz"); ok {
+		t.Fatal("deleted key Baz still found")
+	}
+	if got, want := idx.Keys(), []string{"BAR", "foo"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys = %q, want %q", got, want)
+	}
+	idx.Insert("FOO", 5, 5)
+	if got, want := idx.Keys(), []string{"BAR", "FOO"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys after re-insert = %q, want %q", got, want)
+	}
+
+	out := make([]Result, 3)
+	idx.GetBatch([]string{"bar", "Foo", "nope"}, out)
+	if want := []Result{{3, 3, true}, {5, 5, true}, {}}; !reflect.DeepEqual(out, want) {
+		t.Fatalf("GetBatch = %v, want %v", out, want)
+	}
+
+	// Compact and Snapshot keep both the matching and the display form.
+	idx.Compact()
+	snap := idx.Snapshot()
+	if size, _, ok := snap.Get("foo"); !ok || size != 5 || !reflect.DeepEqual(snap.Keys(), []string{"BAR", "FOO"}) {
+		t.Fatalf("after Compact and Snapshot: Get(foo) = %d, %v; Keys = %q", size, ok, snap.Keys())
+	}
+
+	// The default stays case-sensitive.
+	plain := New()
+	plain.Insert("Foo", 1, 1)
+	if _, _, ok := plain.Get("foo"); ok {
+		t.Fatal("default index matched a different case")
+	}
+}
+
+func TestBuildParallelNormalized(t *testing.T) {
+	in := "4\nFoo 1 1\nfoo 2 2\nBar 3 3\n- BAR\n0\n"
+	idx, err := BuildParallel(context.Background(), strings.NewReader(in), 2, WithKeyNormalizer(strings.ToLower))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size, _, ok := idx.Get("FOO"); !ok || size != 2 || idx.Len() != 1 {
+		t.Fatalf("Get(FOO) = %d, %v; Len = %d", size, ok, idx.Len())
+	}
+}
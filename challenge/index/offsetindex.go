@@ -0,0 +1,19 @@
+package index
+
+// NewWithOffsetIndex is NewWithCapacity(n) plus a secondary index ordered
+// by offset for OffsetRange, kept the same way as NewWithSizeIndex keeps
+// its size order.
+func NewWithOffsetIndex(n int, opts ...Option) *Index {
+	i := NewWithCapacity(n, opts...)
+	i.offsetIx = true
+	return i
+}
+
+// OffsetRange returns the live entries with lo <= offset <= hi, ordered by
+// offset then key: the blobs stored in a byte window of the packed file,
+// which Overlaps can then check for shared bytes. lo > hi yields nil.
+// Without NewWithOffsetIndex, and on a mapped or sharded index, it scans
+// every entry.
+func (i *Index) OffsetRange(lo, hi uint64) []Entry {
+	return i.entryRange(lo, hi, i.offsetIx, &i.byOffset, offsetField)
+}
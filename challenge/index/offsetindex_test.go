@@ -0,0 +1,47 @@
+package index
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestOffsetRange(t *testing.T) {
+	plain, indexed := New(), NewWithOffsetIndex(0)
+	for _, idx := range []*Index{plain, indexed} {
+		for n := 0; n < 300; n++ {
+			idx.Insert(fmt.Sprintf("key%03d", n), 1, uint64(n%100)*1000)
+		}
+		idx.Insert("key000", 1, 1<<40) // overwrite moves key000 out of [0, 0]
+		idx.Delete("key100")
+	}
+	tests := []struct {
+		lo, hi uint64
+		want   int
+	}{
+		{0, 0, 1}, // key200 only
+		{10000, 19000, 30},
+		{10000, 18999, 27}, // both bounds inclusive
+		{99000, 99000, 3},
+		{0, 1<<64 - 1, 299},
+		{1 << 40, 1 << 40, 1},
+		{20000, 10000, 0},
+	}
+	for _, tt := range tests {
+		got := indexed.OffsetRange(tt.lo, tt.hi)
+		if len(got) != tt.want {
+			t.Fatalf("OffsetRange(%d, %d) returned %d entries, want %d", tt.lo, tt.hi, len(got), tt.want)
+		}
+		for j, e := range got {
+			if e.Offset < tt.lo || e.Offset > tt.hi {
+				t.Fatalf("OffsetRange(%d, %d) returned %+v", tt.lo, tt.hi, e)
+			}
+			if j > 0 && (e.Offset < got[j-1].Offset || e.Offset == got[j-1].Offset && e.Key <= got[j-1].Key) {
+				t.Fatalf("OffsetRange(%d, %d) out of order at %d: %v", tt.lo, tt.hi, j, got)
+			}
+		}
+		if scan := plain.OffsetRange(tt.lo, tt.hi); !reflect.DeepEqual(scan, got) {
+			t.Fatalf("OffsetRange(%d, %d): scan %v != offset index %v", tt.lo, tt.hi, scan, got)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package index
+
+// Option configures an index at construction; pass options to New or any
+// of the NewWith constructors.
+type Option func(*Index)
+
+// WithHasher replaces the default 64-bit FNV-1a key hash with h, for
+// trying xxHash, maphash or a deliberately weak hash against Stats. h must
+// be deterministic for the life of the index. It runs once per Insert,
+// Delete and lookup; the table keeps each key's hash, so growth never
+// calls it again. Indexes built with -tags stdmap only use h for the
+// Bloom filter, and Load and OpenMmap always use the default.
+func WithHasher(h func(key string) uint64) Option {
+	return func(i *Index) { i.hasher = h }
+}
+
+// WithInterning keeps a canonical arena copy of every key ever inserted,
+// so a key that is deleted and inserted again reuses its first bytes
+// instead of appending new ones. Overwrites of a live key share its bytes
+// without this option; interning only pays off on delete-heavy churn. It
+// costs one map lookup per new key and a map entry per distinct key that
+// is kept even after the key is deleted.
+func WithInterning() Option {
+	return func(i *Index) { i.intern = map[string]keyRef{} }
+}
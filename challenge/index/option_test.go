@@ -0,0 +1,65 @@
+package index
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWithHasher(t *testing.T) {
+	calls := 0
+	counting := func(key string) uint64 {
+		calls++
+		return FNV1a(key)
+	}
+	idx := NewWithBloom(0, 0.01, WithHasher(counting))
+	const n = 1000 // enough to grow the table several times
+	for k := 0; k < n; k++ {
+		idx.Insert(fmt.Sprintf("key%d", k), uint64(k), 0)
+	}
+	if calls != n {
+		t.Fatalf("hasher called %d times for %d inserts, want %d", calls, n, n)
+	}
+
+	// A constant hash puts every key in one probe run: lookups must stay
+	// correct, and the damage shows up in Stats.
+	bad := New(WithHasher(func(string) uint64 { return 42 }))
+	for k := 0; k < n; k++ {
+		bad.Insert(fmt.Sprintf("key%d", k), uint64(k), 0)
+	}
+	bad.Delete("key7")
+	for k := 0; k < n; k++ {
+		size, _, ok := bad.Get(fmt.Sprintf("key%d", k))
+		if want := k != 7; ok != want || ok && size != uint64(k) {
+			t.Fatalf("Get(key%d) = %d, %v", k, size, ok)
+		}
+	}
+	// The stdmap build reports MaxProbe 0: unknown.
+	if s := bad.Stats(); s.MaxProbe != 0 && s.MaxProbe < n/2 {
+		t.Fatalf("constant hasher MaxProbe = %d, want about %d", s.MaxProbe, n)
+	}
+}
+
+func TestWithInterning(t *testing.T) {
+	plain, interned := New(), New(WithInterning())
+	for _, idx := range []*Index{plain, interned} {
+		for n := 0; n < 100; n++ {
+			idx.Insert("churn", uint64(n), 0)
+			idx.Delete("churn")
+			idx.Insert(fmt.Sprintf("k%d", n%10), uint64(n), 1)
+		}
+	}
+	if got := plain.Stats().KeyBytes; got != 100*5+10*2 {
+		t.Fatalf("plain KeyBytes = %d, want %d", got, 100*5+10*2)
+	}
+	if got := interned.Stats().KeyBytes; got != 5+10*2 {
+		t.Fatalf("interned KeyBytes = %d, want %d", got, 5+10*2)
+	}
+	for n := 0; n < 10; n++ {
+		if size, _, ok := interned.Get(fmt.Sprintf("k%d", n)); !ok || size != uint64(90+n) {
+			t.Fatalf("Get(k%d) = %d, %v", n, size, ok)
+		}
+	}
+	if _, _, ok := interned.Get("churn"); ok {
+		t.Fatal("deleted key found")
+	}
+}
@@ -0,0 +1,43 @@
+package index
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// NotFound is printed for a query whose key is not in the index.
+const NotFound = "NOTFOUND"
+
+// ResultWriter formats query results as text lines, "size offset" for a
+// hit and NotFound for a miss. Output is batched in a 1MB buffer and
+// integers are formatted into a reused scratch slice, so answering a query
+// neither allocates nor issues a syscall. Call Flush when done.
+type ResultWriter struct {
+	w   *bufio.Writer
+	buf []byte
+}
+
+// NewResultWriter returns a ResultWriter writing to w.
+func NewResultWriter(w io.Writer) *ResultWriter {
+	return &ResultWriter{w: bufio.NewWriterSize(w, 1<<20), buf: make([]byte, 0, 32)}
+}
+
+// WriteResult writes one query result line.
+func (rw *ResultWriter) WriteResult(size, offset uint64, found bool) error {
+	if !found {
+		b := append(rw.buf[:0], NotFound...)
+		_, err := rw.w.Write(append(b, '\n'))
+		return err
+	}
+	b := strconv.AppendUint(rw.buf[:0], size, 10)
+	b = append(b, ' ')
+	b = strconv.AppendUint(b, offset, 10)
+	_, err := rw.w.Write(append(b, '\n'))
+	return err
+}
+
+// Flush writes any buffered output to the underlying writer.
+func (rw *ResultWriter) Flush() error {
+	return rw.w.Flush()
+}
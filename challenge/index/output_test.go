@@ -0,0 +1,43 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestResultWriter(t *testing.T) {
+	var buf bytes.Buffer
+	rw := NewResultWriter(&buf)
+	rw.WriteResult(102400, 0, true)
+	rw.WriteResult(0, 0, false)
+	rw.WriteResult(4294967295, 622592, true)
+	if err := rw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "102400 0\nNOTFOUND\n4294967295 622592\n"
+	if buf.String() != want {
+		t.Fatalf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+// BenchmarkOutputFprintln is the unbuffered fmt-per-line baseline that
+// BenchmarkResultWriter replaces.
+func BenchmarkOutputFprintln(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		if n%2 == 0 {
+			fmt.Fprintln(io.Discard, uint64(n), uint64(n*7))
+		} else {
+			fmt.Fprintln(io.Discard, NotFound)
+		}
+	}
+}
+
+func BenchmarkResultWriter(b *testing.B) {
+	rw := NewResultWriter(io.Discard)
+	for n := 0; n < b.N; n++ {
+		rw.WriteResult(uint64(n), uint64(n*7), n%2 == 0)
+	}
+	rw.Flush()
+}
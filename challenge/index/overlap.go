@@ -0,0 +1,42 @@
+package index
+
+import "sort"
+
+// Overlaps reports every pair of live keys whose byte ranges
+// [offset, offset+size) intersect, as {first, second} with first at the
+// lower offset (lower key on equal offsets). Zero-size entries occupy no
+// bytes and never overlap anything.
+//
+// Entries are sorted by offset and swept once while tracking the ranges
+// still open, so the cost is O(n log n) plus the number of pairs reported;
+// heavily overlapping input, such as a random corpus, yields a quadratic
+// number of pairs.
+func (i *Index) Overlaps() [][2]string {
+	var es []Entry
+	i.eachEntry(func(e Entry) {
+		if e.Size > 0 {
+			es = append(es, e)
+		}
+	})
+	sort.Slice(es, func(a, b int) bool {
+		if es[a].Offset != es[b].Offset {
+			return es[a].Offset < es[b].Offset
+		}
+		return es[a].Key < es[b].Key
+	})
+	var out [][2]string
+	var open []Entry // ranges that may still reach the current offset, in offset order
+	for _, e := range es {
+		// a.Offset <= e.Offset, so this is end(a) <= e.Offset without
+		// overflowing on ranges that end near 1<<64.
+		kept := open[:0]
+		for _, a := range open {
+			if e.Offset-a.Offset < a.Size {
+				kept = append(kept, a)
+				out = append(out, [2]string{a.Key, e.Key})
+			}
+		}
+		open = append(kept, e)
+	}
+	return out
+}
@@ -0,0 +1,32 @@
+package index
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOverlaps(t *testing.T) {
+	idx := New()
+	idx.Insert("a", 10, 0)        // [0, 10)
+	idx.Insert("b", 5, 10)        // [10, 15): touches a, no overlap
+	idx.Insert("c", 10, 12)       // [12, 22): overlaps b
+	idx.Insert("d", 0, 13)        // empty: never overlaps
+	idx.Insert("e", 1, 21)        // [21, 22): overlaps c
+	idx.Insert("f", 3, 12)        // [12, 15): overlaps b and c
+	idx.Insert("g", 1<<63, 1<<63) // [2^63, 2^64)
+	idx.Insert("h", 1, 1<<64-1)   // last byte: overlaps g
+	idx.Insert("x", 100, 0)       // deleted below
+	idx.Delete("x")
+
+	want := [][2]string{
+		{"b", "c"}, {"b", "f"}, {"c", "f"},
+		{"c", "e"},
+		{"g", "h"},
+	}
+	if got := idx.Overlaps(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Overlaps() = %v, want %v", got, want)
+	}
+	if got := New().Overlaps(); got != nil {
+		t.Fatalf("empty Overlaps() = %v", got)
+	}
+}
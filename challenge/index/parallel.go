@@ -0,0 +1,62 @@
+package index
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Result is the answer to one query.
+type Result struct {
+	Size   uint64
+	Offset uint64
+	Found  bool
+}
+
+// Freeze marks the index read-only. After Freeze, Insert and Delete panic,
+// and Get may be called from any number of goroutines at once: lookups
+// only read the table and records, they never mutate them. Freeze must
+// happen before the goroutines that read the index are started.
+func (i *Index) Freeze() {
+	i.frozen = true
+}
+
+// Frozen reports whether Freeze has been called.
+func (i *Index) Frozen() bool {
+	return i.frozen
+}
+
+func (i *Index) mustNotBeFrozen(op string) {
+	if i.frozen {
+		panic("index: " + op + " on frozen index")
+	}
+}
+
+// GetParallel answers keys[j] into out[j] using up to workers goroutines
+// (GOMAXPROCS if workers <= 0), each handling one contiguous slice of the
+// input, so out lines up with keys regardless of scheduling. out must have
+// len(keys) elements. The index must be frozen; GetParallel panics
+// otherwise.
+func (i *Index) GetParallel(keys []string, out []Result, workers int) {
+	if !i.frozen {
+		panic("index: GetParallel on unfrozen index")
+	}
+	if len(out) < len(keys) {
+		panic("index: GetParallel out shorter than keys")
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = max(1, min(workers, len(keys)))
+	chunk := (len(keys) + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for lo := 0; lo < len(keys); lo += chunk {
+		hi := min(lo+chunk, len(keys))
+		wg.Add(1)
+		go func(keys []string, out []Result) {
+			defer wg.Done()
+			i.GetBatch(keys, out)
+		}(keys[lo:hi], out[lo:hi])
+	}
+	wg.Wait()
+}
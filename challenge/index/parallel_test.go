@@ -0,0 +1,55 @@
+package index
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetParallel(t *testing.T) {
+	blobs, queries := corpus(t, 10000, 5000)
+	idx := NewWithCapacity(len(blobs))
+	for n, k := range blobs {
+		idx.Insert(k, uint64(n), uint64(n*3))
+	}
+	idx.Freeze()
+
+	for _, workers := range []int{0, 1, 3, 8, 10000} {
+		out := make([]Result, len(queries))
+		idx.GetParallel(queries, out, workers)
+		for j, k := range queries {
+			size, offset, ok := idx.Get(k)
+			if out[j] != (Result{size, offset, ok}) {
+				t.Fatalf("workers=%d: out[%d] = %+v, want %d %d %v", workers, j, out[j], size, offset, ok)
+			}
+		}
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	idx := New()
+	idx.Insert("foo", 1, 1)
+	idx.Freeze()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Insert after Freeze did not panic")
+		}
+	}()
+	idx.Insert("bar", 2, 2)
+}
+
+func BenchmarkGetParallel(b *testing.B) {
+	blobs, queries := corpus(b, 1000000, 100000)
+	idx := NewWithCapacity(len(blobs))
+	for n, k := range blobs {
+		idx.Insert(k, uint64(n), uint64(n))
+	}
+	idx.Freeze()
+	out := make([]Result, len(queries))
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				idx.GetParallel(queries, out, workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package index
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// On-disk index layout, all integers little-endian:
+//
+//	[0:4]   magic "FBIX"
+//	[4:8]   uint32 version
+//	[8:16]  uint64 count, live entries
+//	[16:24] uint64 keyBytes, length of the key arena
+//	[24:]   key arena: every key's bytes back to back
+//	then    count entries of entrySize bytes, sorted by key:
+//	        uint64 key offset in the arena, uint32 key length,
+//	        uint32 checksum (Entry.Checksum, 0 for none), uint64 size,
+//	        uint64 offset
+//
+// Entries are fixed-size and sorted so the file can be searched in place
+// without building a hash table.
+const (
+	fileMagic   = "FBIX"
+	fileVersion = 2 // 2: 64-bit size and offset
+	headerSize  = 24
+	entrySize   = 32
+)
+
+// ErrBadFormat is returned by Load for input that is not a saved index,
+// was written by an incompatible version, or is truncated or inconsistent.
+var ErrBadFormat = errors.New("index: bad index file")
+
+// Save writes the live entries of the index to w in the on-disk format.
+// Overwritten and deleted records and expired InsertWithTTL entries are
+// not written.
+func (i *Index) Save(w io.Writer) error {
+	if i.m != nil {
+		_, err := w.Write(i.m.data)
+		return err
+	}
+	keys := i.sortedKeys()
+	var keyBytes uint64
+	for _, k := range keys {
+		keyBytes += uint64(len(k))
+	}
+
+	bw := bufio.NewWriterSize(w, 1<<20)
+	le := binary.LittleEndian
+	hdr := make([]byte, 0, headerSize)
+	hdr = append(hdr, fileMagic...)
+	hdr = le.AppendUint32(hdr, fileVersion)
+	hdr = le.AppendUint64(hdr, uint64(len(keys)))
+	hdr = le.AppendUint64(hdr, keyBytes)
+	bw.Write(hdr)
+	for _, k := range keys {
+		bw.WriteString(k)
+	}
+
+	buf := make([]byte, 0, entrySize)
+	var off uint64
+	for _, k := range keys {
+		e, _ := i.lookupEntry(i.norm(k))
+		buf = le.AppendUint64(buf[:0], off)
+		buf = le.AppendUint32(buf, uint32(len(k)))
+		buf = le.AppendUint32(buf, e.Checksum)
+		buf = le.AppendUint64(buf, e.Size)
+		buf = le.AppendUint64(buf, e.Offset)
+		bw.Write(buf)
+		off += uint64(len(k))
+	}
+	return bw.Flush()
+}
+
+// Load reads an index written by Save. The header is checked first, so a
+// file of the wrong kind or version fails before any data is read.
+func Load(r io.Reader) (*Index, error) {
+	br := bufio.NewReaderSize(r, 1<<20)
+	hdr := make([]byte, headerSize)
+	if _, err := io.ReadFull(br, hdr); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrBadFormat, err)
+	}
+	if string(hdr[:4]) != fileMagic {
+		return nil, fmt.Errorf("%w: bad magic %q", ErrBadFormat, hdr[:4])
+	}
+	le := binary.LittleEndian
+	if v := le.Uint32(hdr[4:]); v != fileVersion {
+		return nil, fmt.Errorf("%w: version %d, want %d", ErrBadFormat, v, fileVersion)
+	}
+	count, keyBytes := le.Uint64(hdr[8:]), le.Uint64(hdr[16:])
+
+	// The counts are untrusted: read through a limit so a bogus header
+	// runs out of input instead of allocating its claimed size up front.
+	arena, err := io.ReadAll(io.LimitReader(br, int64(min(keyBytes, 1<<62))))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(arena)) != keyBytes {
+		return nil, fmt.Errorf("%w: key arena truncated at %d of %d bytes", ErrBadFormat, len(arena), keyBytes)
+	}
+
+	idx := NewWithCapacity(int(min(count, inputCapacityHint)))
+	ent := make([]byte, entrySize)
+	ahead := uint64(inputCapacityHint)
+	for n := uint64(0); n < count; n++ {
+		if n == ahead {
+			ahead = min(count, 8*n)
+			idx.reserve(int(min(ahead, maxCapacityHint)))
+		}
+		if _, err := io.ReadFull(br, ent); err != nil {
+			return nil, fmt.Errorf("%w: entry %d of %d: %v", ErrBadFormat, n+1, count, err)
+		}
+		off, klen := le.Uint64(ent), uint64(le.Uint32(ent[8:]))
+		if off > keyBytes || klen > keyBytes-off {
+			return nil, fmt.Errorf("%w: entry %d: key out of range", ErrBadFormat, n+1)
+		}
+		idx.InsertEntry(Entry{
+			Key:      string(arena[off : off+klen]),
+			Size:     le.Uint64(ent[16:]),
+			Offset:   le.Uint64(ent[24:]),
+			Checksum: le.Uint32(ent[12:]),
+		})
+	}
+	return idx, nil
+}
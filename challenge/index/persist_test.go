@@ -0,0 +1,106 @@
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	blobs, queries := corpus(t, 5000, 2000)
+	idx := NewWithCapacity(len(blobs))
+	for n, k := range blobs {
+		idx.Insert(k, uint64(n), uint64(n*7))
+	}
+	idx.Insert(blobs[0], 1, 1)
+	idx.Delete(blobs[1])
+	idx.Insert("big", 1<<40, 5_000_000_000)
+
+	var buf bytes.Buffer
+	if err := idx.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != idx.Len() {
+		t.Fatalf("Len() = %d, want %d", loaded.Len(), idx.Len())
+	}
+	for _, k := range append(append(queries, blobs...), "big") {
+		s1, o1, ok1 := idx.Get(k)
+		s2, o2, ok2 := loaded.Get(k)
+		if s1 != s2 || o1 != o2 || ok1 != ok2 {
+			t.Fatalf("Get(%s) = %d %d %v after load, want %d %d %v", k, s2, o2, ok2, s1, o1, ok1)
+		}
+	}
+}
+
+func TestLoadBadFormat(t *testing.T) {
+	idx := New()
+	idx.Insert("foo", 1, 2)
+	var buf bytes.Buffer
+	if err := idx.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	good := buf.Bytes()
+
+	badMagic := append([]byte("XXXX"), good[4:]...)
+	badVersion := append([]byte(nil), good...)
+	badVersion[4] = 99
+	cases := map[string][]byte{
+		"empty":     nil,
+		"magic":     badMagic,
+		"version":   badVersion,
+		"truncated": good[:len(good)-1],
+		"no arena":  good[:headerSize+1],
+	}
+	for name, in := range cases {
+		if _, err := Load(bytes.NewReader(in)); !errors.Is(err, ErrBadFormat) {
+			t.Errorf("%s: err = %v, want ErrBadFormat", name, err)
+		}
+	}
+
+	// A bare header claiming 2M entries must fail before reserving them.
+	bogus := append([]byte(nil), good[:headerSize]...)
+	binary.LittleEndian.PutUint64(bogus[8:], 2000000)
+	binary.LittleEndian.PutUint64(bogus[16:], 0)
+	var err error
+	if n := allocated(func() { _, err = Load(bytes.NewReader(bogus)) }); n > 8<<20 {
+		t.Errorf("bogus count: Load allocated %d bytes", n)
+	}
+	if !errors.Is(err, ErrBadFormat) {
+		t.Errorf("bogus count: err = %v, want ErrBadFormat", err)
+	}
+}
+
+// allocated returns the bytes fn allocates.
+func allocated(fn func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	fn()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+// BenchmarkLoad is the reload counterpart of BenchmarkReadIndex.
+func BenchmarkLoad(b *testing.B) {
+	blobs, _ := corpus(b, 100000, 0)
+	idx := NewWithCapacity(len(blobs))
+	for n, k := range blobs {
+		idx.Insert(k, uint64(n), uint64(n))
+	}
+	var buf bytes.Buffer
+	if err := idx.Save(&buf); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(buf.Len()))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := Load(bytes.NewReader(buf.Bytes())); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
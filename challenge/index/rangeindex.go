@@ -0,0 +1,74 @@
+package index
+
+import (
+	"sort"
+	"strings"
+)
+
+// field selects the uint64 a secondary index orders entries by, from a
+// record or from an Entry.
+type field struct {
+	rec   func(r *record) uint64
+	entry func(e *Entry) uint64
+}
+
+var (
+	sizeField   = field{func(r *record) uint64 { return r.size }, func(e *Entry) uint64 { return e.Size }}
+	offsetField = field{func(r *record) uint64 { return r.offset }, func(e *Entry) uint64 { return e.Offset }}
+)
+
+// order returns live record positions ordered by f, then key, building
+// them into *cache if it is nil (stale).
+func (i *Index) order(cache *[]int32, f field) []int32 {
+	if *cache == nil && i.count() > 0 {
+		pos := make([]int32, 0, i.count())
+		i.each(func(p int32) { pos = append(pos, p) })
+		sort.Slice(pos, func(a, b int) bool {
+			ra, rb := &i.records[pos[a]], &i.records[pos[b]]
+			if va, vb := f.rec(ra), f.rec(rb); va != vb {
+				return va < vb
+			}
+			return i.keys.str(ra.key) < i.keys.str(rb.key)
+		})
+		*cache = pos
+	}
+	return *cache
+}
+
+// entryRange returns the live, unexpired entries with lo <= f <= hi,
+// ordered by f then key, with keys copied out as in Keys. lo > hi yields
+// nil. With
+// indexed set it binary-searches the order cached in *cache; without it,
+// and on a mapped or sharded index, it scans every entry.
+func (i *Index) entryRange(lo, hi uint64, indexed bool, cache *[]int32, f field) []Entry {
+	if lo > hi {
+		return nil
+	}
+	var out []Entry
+	if !indexed || i.m != nil || i.shards != nil {
+		i.eachEntry(func(e Entry) {
+			if v := f.entry(&e); v >= lo && v <= hi {
+				e.Key = strings.Clone(e.Key)
+				out = append(out, e)
+			}
+		})
+		sort.Slice(out, func(a, b int) bool {
+			if va, vb := f.entry(&out[a]), f.entry(&out[b]); va != vb {
+				return va < vb
+			}
+			return out[a].Key < out[b].Key
+		})
+		return out
+	}
+	pos := i.order(cache, f)
+	j := sort.Search(len(pos), func(j int) bool { return f.rec(&i.records[pos[j]]) >= lo })
+	for ; j < len(pos) && f.rec(&i.records[pos[j]]) <= hi; j++ {
+		e := i.records[pos[j]].entry(&i.keys)
+		if i.expired(i.norm(e.Key)) {
+			continue
+		}
+		e.Key = strings.Clone(e.Key)
+		out = append(out, e)
+	}
+	return out
+}
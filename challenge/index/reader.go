@@ -0,0 +1,413 @@
+package index
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// MaxLineSize is the longest input line Reader accepts, including the key
+// and both integers. Longer lines are reported as an error rather than
+// being split or dropped.
+const MaxLineSize = 1 << 20
+
+// ParseError reports a malformed input line.
+type ParseError struct {
+	Line  int    // 1-based input line number
+	Field string // offending field ("N", "Q", "key", "size", "offset"), or "" for the line as a whole
+	Msg   string
+}
+
+func (e *ParseError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Field, e.Msg)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Reader streams the challenge text format:
+//
+//	N
+//	N lines: [+] key size offset [checksum] | - key
+//	Q
+//	Q lines: key
+//
+// Blob lines are inserted into the index as they are read, so peak memory
+// is the index itself plus one line buffer. Fields are separated by spaces
+// or tabs; blank lines are ignored. The optional checksum is the blob's
+// CRC-32 (IEEE) in hex, kept in Entry.Checksum for VerifyAgainst. Input
+// that ends before the N or Q line reads that count as 0; input that ends
+// inside a section is an error.
+//
+// A key that starts with '"' is a Go double-quoted string literal, so keys
+// containing spaces, tabs or quotes can be written as "hello world".
+// Quoting applies to blob, delete and query keys alike; a quoted "+" or "-"
+// is a key, not an opcode.
+//
+// Lines are tokenized and integers decoded by hand over the scanner's
+// byte buffer, so the only per-line allocation is the key string handed to
+// the index.
+type Reader struct {
+	sc     *bufio.Scanner
+	line   int
+	fields [][]byte
+
+	// Set by SetDelimited.
+	csv    *csv.Reader
+	row    *rowSource
+	header bool
+}
+
+// NewReader returns a Reader reading from r.
+func NewReader(r io.Reader) *Reader {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), MaxLineSize)
+	return &Reader{sc: sc, fields: make([][]byte, 0, 4)}
+}
+
+func (r *Reader) errorf(format string, args ...any) error {
+	return &ParseError{Line: r.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// fieldErrorf is errorf for a single bad field on the current line.
+func (r *Reader) fieldErrorf(field, format string, args ...any) error {
+	return &ParseError{Line: r.line, Field: field, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next returns the fields of the next non-blank line. The returned slices
+// alias the scanner buffer and are valid until the following call.
+func (r *Reader) next() ([][]byte, error) {
+	for r.sc.Scan() {
+		r.line++
+		if f := splitFields(r.sc.Bytes(), r.fields[:0]); len(f) > 0 {
+			r.fields = f[:0]
+			return f, nil
+		}
+	}
+	if err := r.sc.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, &ParseError{Line: r.line + 1, Msg: fmt.Sprintf("longer than %d bytes", MaxLineSize)}
+		}
+		return nil, err
+	}
+	return nil, io.ErrUnexpectedEOF
+}
+
+// splitFields appends the space- or tab-separated fields of line to dst.
+// A trailing '\r' is treated as whitespace so CRLF input parses. A field
+// starting with '"' runs to the closing unescaped quote, whitespace
+// included; the quotes stay in the field for key to decode.
+func splitFields(line []byte, dst [][]byte) [][]byte {
+	for j := 0; j < len(line); {
+		if isSpace(line[j]) {
+			j++
+			continue
+		}
+		start := j
+		if line[j] == '"' {
+			for j++; j < len(line) && line[j] != '"'; j++ {
+				if line[j] == '\\' {
+					j++
+				}
+			}
+			j = min(j+1, len(line))
+		}
+		// Anything glued to a closing quote stays in the field, so key
+		// rejects it instead of it becoming a field of its own.
+		for j < len(line) && !isSpace(line[j]) {
+			j++
+		}
+		dst = append(dst, line[start:j])
+	}
+	return dst
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\r' }
+
+// key decodes a key field, unquoting it if it starts with '"'.
+func (r *Reader) key(b []byte) (string, error) {
+	if b[0] != '"' {
+		return string(b), nil
+	}
+	k, err := strconv.Unquote(string(b))
+	if err != nil {
+		return "", r.fieldErrorf("key", "invalid quoted key %s", b)
+	}
+	return k, nil
+}
+
+// parseUint64 decodes a non-empty run of ASCII digits, rejecting any other
+// byte and values above MaxUint64.
+func parseUint64(b []byte) (uint64, bool) {
+	if len(b) == 0 {
+		return 0, false
+	}
+	var v uint64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		d := uint64(c - '0')
+		if v > (1<<64-1-d)/10 {
+			return 0, false
+		}
+		v = v*10 + d
+	}
+	return v, true
+}
+
+// count reads a section header line holding a single count. Input that
+// ends before the line reads as a count of 0, so an empty input is a valid
+// empty index with no queries, and so is "0" alone.
+func (r *Reader) count(what string) (int, error) {
+	f, err := r.next()
+	if err == io.ErrUnexpectedEOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading %s: %w", what, err)
+	}
+	if len(f) != 1 {
+		return 0, r.errorf("%s: want 1 field, got %d", what, len(f))
+	}
+	n, ok := parseUint64(f[0])
+	if !ok || n > 1<<31-1 {
+		return 0, r.fieldErrorf(what, "invalid count %q", f[0])
+	}
+	return int(n), nil
+}
+
+// ReadIndex reads the blob count and blob lines into a new index
+// configured by opts, pre-sized from the count as the blobs arrive (see
+// inputCapacityHint).
+func (r *Reader) ReadIndex(opts ...Option) (*Index, error) {
+	n, err := r.count("N")
+	if err != nil {
+		return nil, err
+	}
+	idx := NewWithCapacity(min(n, inputCapacityHint), opts...)
+	if err := r.readBlobs(context.Background(), idx, n); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// ctxCheckEvery is how many blobs or queries pass between ctx.Err checks
+// in the context-aware paths.
+const ctxCheckEvery = 4096
+
+// BuildFromReader reads the blob count and blob lines from r into a new
+// index, like NewReader(r).ReadIndex, but returns ctx.Err() shortly after
+// ctx is cancelled; the partial index is discarded. The reader buffers
+// ahead, so whatever follows the blob section in r is consumed but unused.
+// opts configure the new index.
+func BuildFromReader(ctx context.Context, r io.Reader, opts ...Option) (*Index, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	rd := NewReader(r)
+	n, err := rd.count("N")
+	if err != nil {
+		return nil, err
+	}
+	idx := NewWithCapacity(min(n, inputCapacityHint), opts...)
+	if err := rd.readBlobs(ctx, idx, n); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// ReadBlobs reads the blob count and blob lines, applying each to idx.
+func (r *Reader) ReadBlobs(idx *Index) error {
+	n, err := r.count("N")
+	if err != nil {
+		return err
+	}
+	return r.readBlobs(context.Background(), idx, n)
+}
+
+func (r *Reader) readBlobs(ctx context.Context, idx *Index, n int) error {
+	if err := idx.checkMemory(n, 0, 0); err != nil {
+		return err
+	}
+	if err := r.skipHeader(); err != nil {
+		return err
+	}
+	var keyBytes int64
+	ahead := inputCapacityHint
+	for b := 0; b < n; b++ {
+		if b == ahead {
+			ahead = min(n, 8*b)
+			idx.reserve(ahead)
+		}
+		if b%ctxCheckEvery == ctxCheckEvery-1 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		op, err := r.blob(b, n)
+		if err != nil {
+			return err
+		}
+		if keyBytes += int64(len(op.Key)); b%ctxCheckEvery == ctxCheckEvery-1 || b == n-1 {
+			if err := idx.checkMemory(n, b+1, keyBytes); err != nil {
+				return err
+			}
+		}
+		if op.Delete {
+			idx.Delete(op.Key)
+		} else {
+			idx.InsertEntry(op.entry())
+		}
+	}
+	return nil
+}
+
+// BlobOp is one parsed blob line: an insert of Key with Size, Offset and
+// Checksum (0 when the line has none), or with Delete set, a delete of Key.
+type BlobOp struct {
+	Delete   bool
+	Key      string
+	Size     uint64
+	Offset   uint64
+	Checksum uint32
+}
+
+// entry returns the Entry an insert op stores.
+func (op BlobOp) entry() Entry {
+	return Entry{Key: op.Key, Size: op.Size, Offset: op.Offset, Checksum: op.Checksum}
+}
+
+// ReadBlobOps reads the blob count and calls fn for each parsed blob line
+// in order, without applying it to an index. It stops at the first error
+// returned by fn.
+func (r *Reader) ReadBlobOps(fn func(op BlobOp) error) error {
+	n, err := r.count("N")
+	if err != nil {
+		return err
+	}
+	if err := r.skipHeader(); err != nil {
+		return err
+	}
+	for b := 0; b < n; b++ {
+		op, err := r.blob(b, n)
+		if err != nil {
+			return err
+		}
+		if err := fn(op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blob parses blob line b of n.
+func (r *Reader) blob(b, n int) (BlobOp, error) {
+	f, err := r.next()
+	if err == io.ErrUnexpectedEOF {
+		return BlobOp{}, fmt.Errorf("expected %d blobs, got %d: %w", n, b, err)
+	}
+	if err != nil {
+		return BlobOp{}, fmt.Errorf("reading blob %d of %d: %w", b+1, n, err)
+	}
+	if r.csv != nil {
+		return r.delimitedBlob(r.sc.Bytes())
+	}
+	del := false
+	if len(f[0]) == 1 && (f[0][0] == '+' || f[0][0] == '-') {
+		del, f = f[0][0] == '-', f[1:]
+	}
+	if del {
+		if len(f) != 1 {
+			return BlobOp{}, r.errorf("delete: want key, got %d fields", len(f))
+		}
+		k, err := r.key(f[0])
+		return BlobOp{Delete: true, Key: k}, err
+	}
+	switch {
+	case len(f) < 3:
+		return BlobOp{}, r.errorf("want key size offset, got %d fields", len(f))
+	case len(f) > 4:
+		return BlobOp{}, r.errorf("trailing garbage %q after checksum", f[4])
+	}
+	op, err := r.blobFields(f[1], f[2], f[3:])
+	if err != nil {
+		return BlobOp{}, err
+	}
+	op.Key, err = r.key(f[0])
+	return op, err
+}
+
+// blobFields decodes the size, offset and optional checksum fields shared
+// by the text and delimited formats.
+func (r *Reader) blobFields(size, offset []byte, checksum [][]byte) (BlobOp, error) {
+	var op BlobOp
+	var ok bool
+	if op.Size, ok = parseUint64(size); !ok {
+		return BlobOp{}, r.fieldErrorf("size", "invalid integer %q", size)
+	}
+	if op.Offset, ok = parseUint64(offset); !ok {
+		return BlobOp{}, r.fieldErrorf("offset", "invalid integer %q", offset)
+	}
+	if len(checksum) > 0 {
+		if op.Checksum, ok = parseHex32(checksum[0]); !ok {
+			return BlobOp{}, r.fieldErrorf("checksum", "invalid CRC-32 %q, want 1-8 hex digits", checksum[0])
+		}
+	}
+	return op, nil
+}
+
+// parseHex32 decodes 1 to 8 hex digits of either case.
+func parseHex32(b []byte) (uint32, bool) {
+	if len(b) == 0 || len(b) > 8 {
+		return 0, false
+	}
+	var v uint32
+	for _, c := range b {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c -= 'a' - 10
+		case c >= 'A' && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
+		}
+		v = v<<4 | uint32(c)
+	}
+	return v, true
+}
+
+// ReadQueries reads the query count and calls fn for each query key in
+// order. It stops at the first error returned by fn.
+func (r *Reader) ReadQueries(fn func(key string) error) error {
+	q, err := r.count("Q")
+	if err != nil {
+		return err
+	}
+	for i := 0; i < q; i++ {
+		f, err := r.next()
+		if err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("expected %d queries, got %d: %w", q, i, err)
+		}
+		if err != nil {
+			return fmt.Errorf("reading query %d of %d: %w", i+1, q, err)
+		}
+		if len(f) != 1 {
+			return r.errorf("query: want 1 field, got %d", len(f))
+		}
+		k, err := r.key(f[0])
+		if err != nil {
+			return err
+		}
+		if err := fn(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
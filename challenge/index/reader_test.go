@@ -0,0 +1,334 @@
+package index
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quadgate/fluxor-blob/challenge/gen"
+)
+
+func TestReader(t *testing.T) {
+	in := "3\nfoo 1 2\n+ bar 3 4\n- foo\n2\nfoo\nbar\n"
+	r := NewReader(strings.NewReader(in))
+	idx := New()
+	if err := r.ReadBlobs(idx); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	err := r.ReadQueries(func(key string) error {
+		got = append(got, key)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "foo" || got[1] != "bar" {
+		t.Fatalf("queries = %v", got)
+	}
+	if _, _, ok := idx.Get("foo"); ok {
+		t.Fatal("deleted key foo still present")
+	}
+	if size, offset, ok := idx.Get("bar"); !ok || size != 3 || offset != 4 {
+		t.Fatalf("Get(bar) = %d, %d, %v", size, offset, ok)
+	}
+}
+
+func TestReaderChecksum(t *testing.T) {
+	in := "3\nfoo 1 2 DEADbeef\nbar 3 4\nbaz 5 6 0\n0\n"
+	r := NewReader(strings.NewReader(in))
+	idx, err := r.ReadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []Entry{
+		{Key: "foo", Size: 1, Offset: 2, Checksum: 0xdeadbeef},
+		{Key: "bar", Size: 3, Offset: 4},
+		{Key: "baz", Size: 5, Offset: 6},
+	} {
+		if got, ok := idx.Lookup(want.Key); !ok || got != want {
+			t.Fatalf("Lookup(%q) = %+v, %v; want %+v", want.Key, got, ok, want)
+		}
+	}
+	if _, ok := idx.Lookup("nope"); ok {
+		t.Fatal("Lookup(nope) found")
+	}
+
+	r = NewReader(strings.NewReader("1\n\"a,b\",1,2,ff\n"))
+	r.SetDelimited(',', false)
+	if idx, err = r.ReadIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := idx.Lookup("a,b"); e.Checksum != 0xff {
+		t.Fatalf("delimited checksum = %x, want ff", e.Checksum)
+	}
+}
+
+func TestReadBlobOps(t *testing.T) {
+	r := NewReader(strings.NewReader("3\nfoo 1 2\n+ bar 3 4\n- foo\n0\n"))
+	var got []BlobOp
+	if err := r.ReadBlobOps(func(op BlobOp) error {
+		got = append(got, op)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []BlobOp{{Key: "foo", Size: 1, Offset: 2}, {Key: "bar", Size: 3, Offset: 4}, {Delete: true, Key: "foo"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ops = %+v, want %+v", got, want)
+	}
+}
+
+func TestReaderQuotedKeys(t *testing.T) {
+	in := "3\n\"hello world\" 1 2\n\"tab\\tkey\"\t3 4\n\"-\" 5 6\n" +
+		"3\n\"hello world\"\nhello\n\"-\"\n"
+	r := NewReader(strings.NewReader(in))
+	idx := New()
+	if err := r.ReadBlobs(idx); err != nil {
+		t.Fatal(err)
+	}
+	if size, offset, ok := idx.Get("hello world"); !ok || size != 1 || offset != 2 {
+		t.Fatalf("Get(hello world) = %d, %d, %v", size, offset, ok)
+	}
+	if _, _, ok := idx.Get("tab\tkey"); !ok {
+		t.Fatal("Get(tab\\tkey) missing")
+	}
+	var got []string
+	if err := r.ReadQueries(func(key string) error {
+		got = append(got, key)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0] != "hello world" || got[1] != "hello" || got[2] != "-" {
+		t.Fatalf("queries = %q", got)
+	}
+}
+
+// TestReaderGeneratedKeys feeds corpora with awkward key alphabets through
+// the reader and checks every answer against gen's.
+func TestReaderGeneratedKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		alphabet string
+		binary   bool
+	}{
+		{"spaces and quotes", "ab \"", false},
+		{"utf-8", "aä€", false},
+		{"binary", "", true},
+	}
+	for _, tt := range tests {
+		var in, want bytes.Buffer
+		cfg := gen.DefaultConfig()
+		cfg.N, cfg.Q, cfg.KeyLen, cfg.Dup = 2000, 1000, 3, 0.2
+		cfg.Alphabet, cfg.BinaryKeys = tt.alphabet, tt.binary
+		cfg.Answers = &want
+		if err := gen.Generate(&in, cfg); err != nil {
+			t.Fatal(err)
+		}
+		r := NewReader(&in)
+		idx, err := r.ReadIndex()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got bytes.Buffer
+		w := NewResultWriter(&got)
+		if err := r.ReadQueries(func(key string) error {
+			return w.WriteResult(idx.Get(key))
+		}); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("%s: answers differ from generator's expected output", tt.name)
+		}
+	}
+}
+
+func TestReaderEmptyAndTruncated(t *testing.T) {
+	for _, in := range []string{"", "\n\n", "0\n", "0\n0\n"} {
+		r := NewReader(strings.NewReader(in))
+		idx, err := r.ReadIndex()
+		if err != nil {
+			t.Fatalf("%q: ReadIndex: %v", in, err)
+		}
+		if idx.Len() != 0 {
+			t.Fatalf("%q: Len = %d", in, idx.Len())
+		}
+		if err := r.ReadQueries(func(string) error { return errors.New("unexpected query") }); err != nil {
+			t.Fatalf("%q: ReadQueries: %v", in, err)
+		}
+	}
+
+	tests := []struct{ in, want string }{
+		{"5\nfoo 1 2\nbar 3 4\n", "expected 5 blobs, got 2"},
+		{"1\nfoo 1 2\n3\nfoo\n", "expected 3 queries, got 1"},
+	}
+	for _, tt := range tests {
+		r := NewReader(strings.NewReader(tt.in))
+		_, err := r.ReadIndex()
+		if err == nil {
+			err = r.ReadQueries(func(string) error { return nil })
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) || !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%q: err = %v, want %q wrapping io.ErrUnexpectedEOF", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestReaderBogusCount(t *testing.T) {
+	var err error
+	if n := allocated(func() { _, err = NewReader(strings.NewReader("2000000000")).ReadIndex() }); n > 8<<20 {
+		t.Errorf("ReadIndex allocated %d bytes for a bare count", n)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("err = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestReaderLineTooLong(t *testing.T) {
+	in := "1\n" + strings.Repeat("k", MaxLineSize+1) + " 1 2\n0\n"
+	err := NewReader(strings.NewReader(in)).ReadBlobs(New())
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("err = %v, want line 2 overflow error", err)
+	}
+}
+
+func TestReaderMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		line  int
+		field string
+	}{
+		{"missing field", "2\nfoo 1 2\nbar 3\n0\n", 3, ""},
+		{"trailing garbage", "1\nfoo 1 2 ab x\n0\n", 2, ""},
+		{"bad checksum", "1\nfoo 1 2 x\n0\n", 2, "checksum"},
+		{"long checksum", "1\nfoo 1 2 123456789\n0\n", 2, "checksum"},
+		{"bad size", "1\nfoo 12x3 2\n0\n", 2, "size"},
+		{"bad offset", "1\nfoo 1 -2\n0\n", 2, "offset"},
+		{"overflow", "1\nfoo 18446744073709551616 0\n0\n", 2, "size"},
+		{"bad count", "x\n", 1, "N"},
+		{"delete with size", "1\n- foo 1\n0\n", 2, ""},
+		{"unterminated quote", "1\n\"foo 1 2\n0\n", 2, ""},
+		{"garbage after quote", "1\n\"foo\"x 1 2\n0\n", 2, "key"},
+	}
+	for _, tt := range tests {
+		err := NewReader(strings.NewReader(tt.in)).ReadBlobs(New())
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("%s: err = %v, want *ParseError", tt.name, err)
+			continue
+		}
+		if pe.Line != tt.line || pe.Field != tt.field {
+			t.Errorf("%s: line, field = %d, %q, want %d, %q (%v)", tt.name, pe.Line, pe.Field, tt.line, tt.field, err)
+		}
+	}
+}
+
+func TestParseUint64(t *testing.T) {
+	for in, want := range map[string]uint64{"0": 0, "42": 42, "5000000000": 5000000000, "18446744073709551615": 1<<64 - 1} {
+		if got, ok := parseUint64([]byte(in)); !ok || got != want {
+			t.Errorf("parseUint64(%q) = %d, %v", in, got, ok)
+		}
+	}
+	for _, in := range []string{"", "+1", "1.5", "18446744073709551616", "99999999999999999999"} {
+		if _, ok := parseUint64([]byte(in)); ok {
+			t.Errorf("parseUint64(%q) accepted", in)
+		}
+	}
+}
+
+func BenchmarkReadBlobs(b *testing.B) {
+	cfg := gen.DefaultConfig()
+	cfg.N, cfg.Q = 100000, 0
+	var buf bytes.Buffer
+	if err := gen.Generate(&buf, cfg); err != nil {
+		b.Fatal(err)
+	}
+	in := buf.Bytes()
+	b.SetBytes(int64(len(in)))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := NewReader(bytes.NewReader(in)).ReadBlobs(New()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadIndex(b *testing.B) {
+	cfg := gen.DefaultConfig()
+	cfg.N, cfg.Q = 100000, 0
+	var buf bytes.Buffer
+	if err := gen.Generate(&buf, cfg); err != nil {
+		b.Fatal(err)
+	}
+	in := buf.Bytes()
+	b.SetBytes(int64(len(in)))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := NewReader(bytes.NewReader(in)).ReadIndex(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReaderDelimited(t *testing.T) {
+	tests := []struct {
+		name   string
+		comma  rune
+		header bool
+		in     string
+	}{
+		{"csv", ',', true, "3\nkey,size,offset\n\"a,b\",1,2\nplain,3,4\n\"say \"\"hi\"\"\",5,6\n"},
+		{"tsv", '\t', false, "3\na,b\t1\t2\r\nplain\t3\t4\n\n\"say \"\"hi\"\"\"\t5\t6\n"},
+	}
+	for _, tt := range tests {
+		r := NewReader(strings.NewReader(tt.in + "2\na,b\n\"say \\\"hi\\\"\"\n"))
+		r.SetDelimited(tt.comma, tt.header)
+		idx := New()
+		if err := r.ReadBlobs(idx); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		want := []struct {
+			key          string
+			size, offset uint64
+		}{{"a,b", 1, 2}, {"plain", 3, 4}, {`say "hi"`, 5, 6}}
+		for _, w := range want {
+			if size, offset, ok := idx.Get(w.key); !ok || size != w.size || offset != w.offset {
+				t.Fatalf("%s: Get(%q) = %d, %d, %v", tt.name, w.key, size, offset, ok)
+			}
+		}
+		var got []string
+		if err := r.ReadQueries(func(key string) error {
+			got = append(got, key)
+			return nil
+		}); err != nil {
+			t.Fatalf("%s: queries: %v", tt.name, err)
+		}
+		if len(got) != 2 || got[0] != "a,b" || got[1] != `say "hi"` {
+			t.Fatalf("%s: queries = %q", tt.name, got)
+		}
+	}
+
+	for _, in := range []string{
+		"1\na,1\n",
+		"1\na,1,2,3,4\n",
+		"1\na,1,2,xyz\n",
+		"1\na,x,2\n",
+		"1\n\"a,1,2\n",
+		"1\na\"b,1,2\n",
+	} {
+		r := NewReader(strings.NewReader(in))
+		r.SetDelimited(',', false)
+		var pe *ParseError
+		if err := r.ReadBlobs(New()); !errors.As(err, &pe) || pe.Line != 2 {
+			t.Errorf("%q: got %v, want a line 2 ParseError", in, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package index
+
+import (
+	"context"
+	"io"
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+// shardBatch is how many parsed blobs the reader hands a shard at once.
+const shardBatch = 1024
+
+// shardOf returns the shard that owns keys hashing to h.
+func (i *Index) shardOf(h uint64) *Index {
+	return i.shards[shardIndex(h, len(i.shards))]
+}
+
+// shardIndex maps h to one of n shards. The hash is remixed first so the
+// shard choice does not correlate with the high bits each shard's table
+// uses for the home slot.
+func shardIndex(h uint64, n int) int {
+	h ^= h >> 31
+	h *= 0xBF58476D1CE4E5B9
+	h ^= h >> 27
+	s, _ := bits.Mul64(h, uint64(n))
+	return int(s)
+}
+
+// shardOp is a parsed blob routed to a shard with its normalized key and
+// hash, so the shard does not compute them again.
+type shardOp struct {
+	op  BlobOp
+	key string
+	h   uint64
+}
+
+// BuildParallel reads the blob count and blob lines from r, like
+// BuildFromReader, into an index split into shards independent sub-tables
+// (GOMAXPROCS if shards <= 0). Keys are partitioned by hash and every
+// shard is filled by its own goroutine, so inserts take no locks. All
+// lines for one key go to the same shard in input order, so
+// last-write-wins and deletes give exactly the serial result.
+//
+// Parsing stays on the calling goroutine and feeds the shards in batches;
+// it is usually the bottleneck, which caps the speedup below the shard
+// count. Get, Insert and Delete on the result route through the same hash,
+// and every other method sees the union of the shards. Cancelling ctx
+// stops the build within a few thousand lines and returns ctx.Err().
+// opts apply to every shard, except that a WithCache cache fronts only
+// the top-level Get.
+func BuildParallel(ctx context.Context, r io.Reader, shards int, opts ...Option) (*Index, error) {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	rd := NewReader(r)
+	n, err := rd.count("N")
+	if err != nil {
+		return nil, err
+	}
+
+	idx := New(opts...)
+	if err := idx.checkMemory(n, 0, 0); err != nil {
+		return nil, err
+	}
+	idx.shards = make([]*Index, shards)
+	feeds := make([]chan []shardOp, shards)
+	var wg sync.WaitGroup
+	for s := range idx.shards {
+		sh := NewWithCapacity(n/shards, opts...)
+		// Only the top-level index fronts Get with a cache or filter.
+		sh.cache, sh.bloom = nil, nil
+		feed := make(chan []shardOp, 4)
+		idx.shards[s], feeds[s] = sh, feed
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range feed {
+				for _, so := range batch {
+					if so.op.Delete {
+						sh.Delete(so.op.Key)
+					} else {
+						sh.insertHashed(so.key, so.h, so.op.entry())
+					}
+				}
+			}
+		}()
+	}
+
+	pending := make([][]shardOp, shards)
+	var keyBytes int64
+	for b := 0; b < n && err == nil; b++ {
+		if b%ctxCheckEvery == ctxCheckEvery-1 {
+			if err = ctx.Err(); err != nil {
+				break
+			}
+		}
+		var op BlobOp
+		if op, err = rd.blob(b, n); err != nil {
+			break
+		}
+		if keyBytes += int64(len(op.Key)); b%ctxCheckEvery == ctxCheckEvery-1 || b == n-1 {
+			if err = idx.checkMemory(n, b+1, keyBytes); err != nil {
+				break
+			}
+		}
+		key := idx.norm(op.Key)
+		h := idx.hash(key)
+		s := shardIndex(h, shards)
+		pending[s] = append(pending[s], shardOp{op, key, h})
+		if len(pending[s]) == shardBatch {
+			feeds[s] <- pending[s]
+			pending[s] = make([]shardOp, 0, shardBatch)
+		}
+	}
+	for s, feed := range feeds {
+		if err == nil && len(pending[s]) > 0 {
+			feed <- pending[s]
+		}
+		close(feed)
+	}
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
@@ -0,0 +1,136 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quadgate/fluxor-blob/challenge/gen"
+)
+
+func TestBuildParallel(t *testing.T) {
+	var in bytes.Buffer
+	cfg := gen.DefaultConfig()
+	cfg.N, cfg.Q, cfg.Dup = 20000, 0, 0.3
+	if err := gen.Generate(&in, cfg); err != nil {
+		t.Fatal(err)
+	}
+	// Delete a few keys on top of the overwrites.
+	lines := strings.SplitAfter(in.String(), "\n")
+	del := ""
+	for _, l := range lines[1:6] {
+		del += "- " + l[:strings.IndexByte(l, ' ')] + "\n"
+	}
+	src := "20005\n" + strings.Join(lines[1:cfg.N+1], "") + del
+
+	serial, err := NewReader(strings.NewReader(src)).ReadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, shards := range []int{1, 3, 8} {
+		par, err := BuildParallel(context.Background(), strings.NewReader(src), shards)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if par.Len() != serial.Len() {
+			t.Fatalf("%d shards: Len() = %d, want %d", shards, par.Len(), serial.Len())
+		}
+		if d := Diff(serial, par); d.Added != nil || d.Removed != nil || d.Modified != nil {
+			t.Fatalf("%d shards: differs from serial build: %+v", shards, d)
+		}
+		keys := serial.Keys()
+		out := make([]Result, len(keys))
+		par.Freeze()
+		par.GetParallel(keys, out, 4)
+		for j, k := range keys {
+			size, offset, _ := serial.Get(k)
+			if out[j] != (Result{size, offset, true}) {
+				t.Fatalf("%d shards: GetParallel(%q) = %+v", shards, k, out[j])
+			}
+		}
+		if s := par.Stats(); s.Entries != serial.Len() || s.Buckets == 0 {
+			t.Fatalf("%d shards: Stats = %+v", shards, s)
+		}
+	}
+
+	// Writes after the build route to the owning shard.
+	par, err := BuildParallel(context.Background(), strings.NewReader(src), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := serial.Keys()[0]
+	par.Insert(k, 1, 2)
+	par.Insert("new key", 3, 4)
+	par.Delete(serial.Keys()[1])
+	if size, offset, ok := par.Get(k); !ok || size != 1 || offset != 2 {
+		t.Fatalf("Get(%q) after Insert = %d, %d, %v", k, size, offset, ok)
+	}
+	if par.Len() != serial.Len() || !reflect.DeepEqual(par.PrefixScan("new"), []string{"new key"}) {
+		t.Fatalf("after writes: Len() = %d, PrefixScan(new) = %v", par.Len(), par.PrefixScan("new"))
+	}
+}
+
+func TestBuildParallelCache(t *testing.T) {
+	idx, err := BuildParallel(context.Background(), strings.NewReader("2\nfoo 1 2\nbar 3 4\n"), 4, WithCache(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sh := range idx.shards {
+		if sh.cache != nil {
+			t.Fatal("a shard has its own cache")
+		}
+	}
+	for _, k := range []string{"foo", "foo", "baz", "foo"} {
+		idx.Get(k)
+	}
+	if s := idx.Stats(); s.CacheHits != 2 || s.CacheMisses != 2 {
+		t.Fatalf("CacheHits, CacheMisses = %d, %d; want 2, 2", s.CacheHits, s.CacheMisses)
+	}
+}
+
+func TestBuildParallelCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := io.MultiReader(strings.NewReader("2000000000\n"), &endlessBlobs{limit: 10000, cancel: cancel})
+	if _, err := BuildParallel(ctx, src, 4); !errors.Is(err, context.Canceled) {
+		t.Fatalf("BuildParallel after cancel: err = %v, want context.Canceled", err)
+	}
+	if _, err := BuildParallel(context.Background(), strings.NewReader("2\nfoo 1 2\n"), 2); err == nil {
+		t.Fatal("truncated input: expected error")
+	}
+}
+
+// BenchmarkBuildParallel compares the serial reader build with sharded
+// builds on the default 1M-blob corpus.
+func BenchmarkBuildParallel(b *testing.B) {
+	var in bytes.Buffer
+	cfg := gen.DefaultConfig()
+	cfg.Q = 0
+	if err := gen.Generate(&in, cfg); err != nil {
+		b.Fatal(err)
+	}
+	data := in.Bytes()
+	b.Run("serial", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for n := 0; n < b.N; n++ {
+			if _, err := NewReader(bytes.NewReader(data)).ReadIndex(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	for _, shards := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for n := 0; n < b.N; n++ {
+				if _, err := BuildParallel(context.Background(), bytes.NewReader(data), shards); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package index
+
+// NewWithSizeIndex is NewWithCapacity(n) plus a secondary index ordered by
+// size, which lets SizeRange binary-search instead of scanning every
+// entry. Like the sorted key set behind PrefixScan, it is built on the
+// first SizeRange after a change, costing O(n log n) time and four bytes
+// per entry; every Insert or Delete invalidates it, so interleaving writes
+// with SizeRange calls rebuilds it each time.
+func NewWithSizeIndex(n int, opts ...Option) *Index {
+	i := NewWithCapacity(n, opts...)
+	i.sizeIx = true
+	return i
+}
+
+// SizeRange returns the live entries with lo <= size <= hi, ordered by size
+// then key. lo > hi yields nil. Without NewWithSizeIndex, and on a mapped
+// or sharded index, it scans every entry.
+func (i *Index) SizeRange(lo, hi uint64) []Entry {
+	return i.entryRange(lo, hi, i.sizeIx, &i.bySize, sizeField)
+}
@@ -0,0 +1,46 @@
+package index
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSizeRange(t *testing.T) {
+	plain, sized := New(), NewWithSizeIndex(0)
+	for _, idx := range []*Index{plain, sized} {
+		for n := 0; n < 300; n++ {
+			idx.Insert(fmt.Sprintf("key%03d", n), uint64(n%100), uint64(n))
+		}
+		idx.Insert("key000", 5000, 1) // overwrite moves key000 out of [0, 0]
+		idx.Delete("key100")
+	}
+	tests := []struct {
+		lo, hi uint64
+		want   int
+	}{
+		{0, 0, 1}, // key200 only
+		{10, 19, 30},
+		{99, 99, 3},
+		{0, 1<<64 - 1, 299},
+		{5000, 5000, 1},
+		{20, 10, 0},
+	}
+	for _, tt := range tests {
+		got := sized.SizeRange(tt.lo, tt.hi)
+		if len(got) != tt.want {
+			t.Fatalf("SizeRange(%d, %d) returned %d entries, want %d", tt.lo, tt.hi, len(got), tt.want)
+		}
+		for j, e := range got {
+			if e.Size < tt.lo || e.Size > tt.hi {
+				t.Fatalf("SizeRange(%d, %d) returned %+v", tt.lo, tt.hi, e)
+			}
+			if j > 0 && (e.Size < got[j-1].Size || e.Size == got[j-1].Size && e.Key <= got[j-1].Key) {
+				t.Fatalf("SizeRange(%d, %d) out of order at %d: %v", tt.lo, tt.hi, j, got)
+			}
+		}
+		if scan := plain.SizeRange(tt.lo, tt.hi); !reflect.DeepEqual(scan, got) {
+			t.Fatalf("SizeRange(%d, %d): scan %v != size index %v", tt.lo, tt.hi, scan, got)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package index
+
+import "maps"
+
+// Snapshot returns a frozen, point-in-time copy of the index that later
+// Insert, Delete or Compact calls on i do not affect. The copy shares i's
+// records and key arena, which are append-only, so it costs one copy of
+// the key table (and the Bloom filter, if any) rather than of the data.
+//
+// Like a frozen index, the snapshot's Get and GetBatch may be called from
+// any number of goroutines, and they stay safe while another goroutine
+// keeps writing to i. Snapshot itself reads i, so it must not run
+// concurrently with writes to i. The snapshot has no NewWithCache cache.
+// A mapped index is already immutable and is returned as is.
+func (i *Index) Snapshot() *Index {
+	if i.m != nil {
+		return i
+	}
+	s := &Index{
+		// Full slice expressions keep the snapshot from ever writing into
+		// capacity that i appends to.
+		records:  i.records[:len(i.records):len(i.records)],
+		keys:     keyArena{chunks: append([][]byte(nil), i.keys.chunks...)},
+		sorted:   i.sorted,
+		bySize:   i.bySize,
+		sizeIx:   i.sizeIx,
+		byOffset: i.byOffset,
+		offsetIx: i.offsetIx,
+		frozen:   true,
+		hasher:   i.hasher,
+		expires:  maps.Clone(i.expires),
+		now:      i.now,
+		dups:     maps.Clone(i.dups),
+
+		normalize: i.normalize,
+	}
+	if i.shards != nil {
+		s.shards = make([]*Index, len(i.shards))
+		for j, sh := range i.shards {
+			s.shards[j] = sh.Snapshot()
+		}
+		return s
+	}
+	s.t = i.tableClone()
+	if i.bloom != nil {
+		b := *i.bloom
+		b.bits = append([]uint64(nil), b.bits...)
+		s.bloom = &b
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package index
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	for _, parent := range []*Index{New(), NewWithBloom(0, 0.01), NewWithSizeIndex(0)} {
+		const n = 1000
+		for k := 0; k < n; k++ {
+			parent.Insert(fmt.Sprintf("key%d", k), uint64(k), 0)
+		}
+		snap := parent.Snapshot()
+		if !snap.Frozen() || parent.Frozen() {
+			t.Fatal("snapshot not frozen, or parent frozen")
+		}
+
+		// Mutate the parent while the snapshot is read concurrently.
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < n; k++ {
+				parent.Insert(fmt.Sprintf("key%d", k), uint64(k), 1)
+				parent.Insert(fmt.Sprintf("new%d", k), 1, 1)
+				if k%2 == 0 {
+					parent.Delete(fmt.Sprintf("key%d", k))
+				}
+			}
+			parent.Compact()
+		}()
+		for r := 0; r < 2; r++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := 0; k < n; k++ {
+					size, offset, ok := snap.Get(fmt.Sprintf("key%d", k))
+					if !ok || size != uint64(k) || offset != 0 {
+						t.Errorf("snapshot Get(key%d) = %d, %d, %v", k, size, offset, ok)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		if _, _, ok := snap.Get("new0"); ok || snap.Len() != n {
+			t.Fatalf("snapshot sees later inserts: Len = %d", snap.Len())
+		}
+		if size, offset, ok := parent.Get("key1"); !ok || size != 1 || offset != 1 {
+			t.Fatalf("parent Get(key1) = %d, %d, %v", size, offset, ok)
+		}
+		if top := snap.TopBySize(1); len(top) != 1 || top[0].Key != fmt.Sprintf("key%d", n-1) {
+			t.Fatalf("snapshot TopBySize = %v", top)
+		}
+	}
+}
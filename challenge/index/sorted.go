@@ -0,0 +1,170 @@
+package index
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// sortedKeys returns the distinct live keys in lexicographic (byte) order.
+// The slice is built on first use after the key set changes, costing
+// O(n log n) time and one string header per key, then reused until the
+// next Insert of a new key or Delete. Expired InsertWithTTL keys stay in
+// it, since they expire without changing the key set, and are filtered
+// out on every call into a fresh O(n) copy while any deadline is set.
+// Callers must not modify the result.
+func (i *Index) sortedKeys() []string {
+	keys := i.storedKeys()
+	if len(i.expires) == 0 {
+		return keys
+	}
+	live := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if !i.expired(i.norm(k)) {
+			live = append(live, k)
+		}
+	}
+	return live
+}
+
+// storedKeys is the cached sortedKeys set, expired keys included.
+func (i *Index) storedKeys() []string {
+	if i.sorted == nil && i.m != nil && i.m.count > 0 {
+		// Mapped entries are already in key order.
+		keys := make([]string, i.m.count)
+		for j := range keys {
+			keys[j] = i.m.key(j)
+		}
+		i.sorted = keys
+	}
+	if i.sorted == nil && i.Len() > 0 {
+		keys := make([]string, 0, i.Len())
+		i.eachStored(func(e Entry) {
+			keys = append(keys, e.Key)
+		})
+		sort.Strings(keys)
+		i.sorted = keys
+	}
+	return i.sorted
+}
+
+// PrefixScan returns all keys starting with prefix in lexicographic order.
+// An empty prefix returns every key. The first call after the key set
+// changes rebuilds the sorted key slice (O(n log n)); subsequent calls are
+// a binary search plus the size of the result.
+func (i *Index) PrefixScan(prefix string) []string {
+	keys := i.sortedKeys()
+	lo := sort.SearchStrings(keys, prefix)
+	hi := lo
+	for hi < len(keys) && strings.HasPrefix(keys[hi], prefix) {
+		hi++
+	}
+	out := make([]string, hi-lo)
+	copy(out, keys[lo:hi])
+	return out
+}
+
+// Glob returns the keys matching the shell-style pattern in lexicographic
+// order, with path.Match semantics: '*' and '?' do not match '/'. A
+// malformed pattern returns path.ErrBadPattern. Only keys starting with
+// the pattern's literal prefix, the part before the first '*', '?', '[' or
+// '\\', are tested, but a pattern that starts with a wildcard tests every
+// key: O(n) on top of the cached sorted key set.
+func (i *Index) Glob(pattern string) ([]string, error) {
+	// Match checks the whole pattern even when the name does not match.
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	prefix := pattern[:strings.IndexAny(pattern+"*", "*?[\\")]
+	keys := i.sortedKeys()
+	var out []string
+	for j := sort.SearchStrings(keys, prefix); j < len(keys) && strings.HasPrefix(keys[j], prefix); j++ {
+		if ok, _ := path.Match(pattern, keys[j]); ok {
+			out = append(out, keys[j])
+		}
+	}
+	return out, nil
+}
+
+// PrefixCounts returns how many keys start with each distinct k-byte
+// prefix. A key shorter than k counts under itself, and k <= 0 puts every
+// key under "". Keys sharing a prefix are adjacent in the cached sorted key
+// set, so this is one O(n) pass over it plus one map entry per prefix; the
+// map's strings are copies, as in Keys.
+func (i *Index) PrefixCounts(k int) map[string]int {
+	keys := i.sortedKeys()
+	if k <= 0 {
+		return map[string]int{"": len(keys)}
+	}
+	counts := make(map[string]int)
+	for j := 0; j < len(keys); {
+		p, n := keys[j][:min(k, len(keys[j]))], 1
+		// Keys longer than a short key p have prefixes of their own.
+		for len(p) == k && j+n < len(keys) && strings.HasPrefix(keys[j+n], p) {
+			n++
+		}
+		counts[strings.Clone(p)] = n
+		j += n
+	}
+	return counts
+}
+
+// Ceiling returns the smallest key >= key, which is key itself when
+// present, or ("", false) if every key is smaller. Like PrefixScan it is a
+// binary search over the cached sorted key set.
+func (i *Index) Ceiling(key string) (string, bool) {
+	keys := i.sortedKeys()
+	if j := sort.SearchStrings(keys, key); j < len(keys) {
+		return keys[j], true
+	}
+	return "", false
+}
+
+// Floor returns the largest key <= key, which is key itself when present,
+// or ("", false) if every key is larger.
+func (i *Index) Floor(key string) (string, bool) {
+	keys := i.sortedKeys()
+	j := sort.SearchStrings(keys, key)
+	if j < len(keys) && keys[j] == key {
+		return key, true
+	}
+	if j > 0 {
+		return keys[j-1], true
+	}
+	return "", false
+}
+
+// Keys returns every key in lexicographic (byte) order. Keys are distinct,
+// so the order is total. The slice and its strings are the caller's: each
+// key is copied out of the key arena or file mapping, so holding the result
+// pins neither and it stays valid after Close.
+func (i *Index) Keys() []string {
+	keys := i.sortedKeys()
+	out := make([]string, len(keys))
+	for j, k := range keys {
+		out[j] = strings.Clone(k)
+	}
+	return out
+}
+
+// ForEach calls fn for every entry in lexicographic key order and stops
+// early if fn returns false. Expired InsertWithTTL entries are skipped. A
+// mapped index is walked in place; a heap index uses the cached sorted key
+// set (built if stale, see sortedKeys) but allocates nothing per call.
+// ForEach does not modify the index, and fn must not either.
+func (i *Index) ForEach(fn func(key string, size, offset uint64) bool) {
+	if i.m != nil {
+		for j := 0; j < i.m.count; j++ {
+			if e := i.m.entry(j); !fn(e.Key, e.Size, e.Offset) {
+				return
+			}
+		}
+		return
+	}
+	for _, k := range i.sortedKeys() {
+		e, _ := i.lookupEntry(i.norm(k))
+		if !fn(k, e.Size, e.Offset) {
+			return
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package index
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestPrefixScan(t *testing.T) {
+	idx := New()
+	for _, k := range []string{"abc", "b", "ab", "abd", "a", "ac"} {
+		idx.Insert(k, 1, 1)
+	}
+
+	if got, want := idx.PrefixScan("ab"), []string{"ab", "abc", "abd"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("PrefixScan(ab) = %v, want %v", got, want)
+	}
+	if got, want := idx.PrefixScan(""), []string{"a", "ab", "abc", "abd", "ac", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("PrefixScan(\"\") = %v, want %v", got, want)
+	}
+	if got := idx.PrefixScan("zz"); len(got) != 0 {
+		t.Fatalf("PrefixScan(zz) = %v, want empty", got)
+	}
+
+	// The sorted view must follow later mutations.
+	idx.Delete("abc")
+	idx.Insert("abz", 1, 1)
+	if got, want := idx.PrefixScan("ab"), []string{"ab", "abd", "abz"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("PrefixScan(ab) after mutation = %v, want %v", got, want)
+	}
+}
+
+func TestKeysForEach(t *testing.T) {
+	idx := New()
+	for _, k := range []string{"b", "a", "c", "ab"} {
+		idx.Insert(k, uint64(len(k)), 7)
+	}
+	idx.Insert("a", 9, 9)
+	want := []string{"a", "ab", "b", "c"}
+	keys := idx.Keys()
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("Keys() = %v, want %v", keys, want)
+	}
+	keys[0] = "mutated"
+	if got := idx.PrefixScan(""); got[0] != "a" {
+		t.Fatal("modifying Keys() result changed the index")
+	}
+
+	path, _ := saveFile(t, idx)
+	m, err := OpenMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	for _, ix := range []*Index{idx, m} {
+		var got []string
+		ix.ForEach(func(key string, size, offset uint64) bool {
+			if key == "a" && (size != 9 || offset != 9) {
+				t.Fatalf("ForEach a = %d, %d, want 9, 9", size, offset)
+			}
+			got = append(got, key)
+			return key != "b"
+		})
+		if !reflect.DeepEqual(got, want[:3]) {
+			t.Fatalf("ForEach visited %v, want %v", got, want[:3])
+		}
+	}
+}
+
+// TestCeilingFloor checks Ceiling and Floor on the generated corpus against
+// a linear scan, for stored keys, random misses and the extremes.
+func TestCeilingFloor(t *testing.T) {
+	blobs, queries := corpus(t, 5000, 1000)
+	idx := New()
+	for _, k := range blobs {
+		idx.Insert(k, 1, 1)
+	}
+	queries = append(queries, "", "a", "zzzzzzzzzzzzzzzzz", blobs[0])
+	for _, q := range queries {
+		var ceil, floor string
+		var hasCeil, hasFloor bool
+		for _, k := range blobs {
+			if k >= q && (!hasCeil || k < ceil) {
+				ceil, hasCeil = k, true
+			}
+			if k <= q && (!hasFloor || k > floor) {
+				floor, hasFloor = k, true
+			}
+		}
+		if k, ok := idx.Ceiling(q); k != ceil || ok != hasCeil {
+			t.Fatalf("Ceiling(%q) = %q, %v; want %q, %v", q, k, ok, ceil, hasCeil)
+		}
+		if k, ok := idx.Floor(q); k != floor || ok != hasFloor {
+			t.Fatalf("Floor(%q) = %q, %v; want %q, %v", q, k, ok, floor, hasFloor)
+		}
+	}
+	if k, ok := New().Ceiling("x"); ok || k != "" {
+		t.Fatalf("empty Ceiling = %q, %v", k, ok)
+	}
+}
+
+func TestGlob(t *testing.T) {
+	idx := New()
+	for _, k := range []string{"img_1.jpg", "img_2.png", "img_10.jpg", "tmp_a", "tmp_b/c", "x*y", "ximg_1.jpg"} {
+		idx.Insert(k, 1, 1)
+	}
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"img_*.jpg", []string{"img_1.jpg", "img_10.jpg"}},
+		{"*.jpg", []string{"img_1.jpg", "img_10.jpg", "ximg_1.jpg"}},
+		{"tmp_*", []string{"tmp_a"}}, // '*' stops at '/'
+		{"tmp_?/?", []string{"tmp_b/c"}},
+		{"img_[0-9].*", []string{"img_1.jpg", "img_2.png"}},
+		{`x\*y`, []string{"x*y"}},
+		{"tmp_a", []string{"tmp_a"}},
+		{"nope*", nil},
+	}
+	for _, tt := range tests {
+		got, err := idx.Glob(tt.pattern)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Glob(%q) = %q, %v; want %q", tt.pattern, got, err, tt.want)
+		}
+	}
+	if _, err := idx.Glob("zzz["); !errors.Is(err, path.ErrBadPattern) {
+		t.Fatalf("Glob(zzz[) err = %v, want path.ErrBadPattern", err)
+	}
+}
+
+func TestPrefixCounts(t *testing.T) {
+	idx := New()
+	for _, k := range []string{"abc", "b", "ab", "abd", "a", "ac", "bcd", "bce", ""} {
+		idx.Insert(k, 1, 1)
+	}
+	tests := []struct {
+		k    int
+		want map[string]int
+	}{
+		{0, map[string]int{"": 9}},
+		{-1, map[string]int{"": 9}},
+		{1, map[string]int{"": 1, "a": 5, "b": 3}},
+		{2, map[string]int{"": 1, "a": 1, "ab": 3, "ac": 1, "b": 1, "bc": 2}},
+		{3, map[string]int{"": 1, "a": 1, "ab": 1, "abc": 1, "abd": 1, "ac": 1, "b": 1, "bcd": 1, "bce": 1}},
+	}
+	for _, tt := range tests {
+		if got := idx.PrefixCounts(tt.k); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PrefixCounts(%d) = %v, want %v", tt.k, got, tt.want)
+		}
+	}
+	if got, want := New().PrefixCounts(0), map[string]int{"": 0}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("empty PrefixCounts(0) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+package index
+
+import "unsafe"
+
+// Stats describes the shape and memory footprint of an Index.
+type Stats struct {
+	Entries    int     // distinct live keys
+	Records    int     // stored records, including overwritten and deleted ones
+	Buckets    int     // hash table slots
+	LoadFactor float64 // Entries / Buckets
+	MaxProbe   int     // longest probe sequence in slots; 0 if the table does not expose it
+	KeyBytes   int64   // key bytes stored; an overwrite shares its key's bytes
+	MemBytes   int64   // estimated bytes held by the key arena, records and the hash table
+
+	ReclaimedBytes int64 // MemBytes freed by Compact over the index's life
+
+	CacheHits   uint64 // Get calls answered by the NewWithCache cache
+	CacheMisses uint64 // Get calls that went to the table
+}
+
+// Stats reports the index's current shape. It scans the table and the
+// record list, so it is O(slots + records).
+func (i *Index) Stats() Stats {
+	if i.m != nil {
+		// A mapped index has no hash table; count the mapping itself.
+		return Stats{
+			Entries:  i.m.count,
+			Records:  i.m.count,
+			KeyBytes: int64(len(i.m.arena)),
+			MemBytes: int64(len(i.m.data)),
+		}
+	}
+	if i.shards != nil {
+		// Shards are independent tables; add them up.
+		var s Stats
+		for _, sh := range i.shards {
+			ss := sh.Stats()
+			s.Entries += ss.Entries
+			s.Records += ss.Records
+			s.Buckets += ss.Buckets
+			s.MaxProbe = max(s.MaxProbe, ss.MaxProbe)
+			s.KeyBytes += ss.KeyBytes
+			s.MemBytes += ss.MemBytes
+			s.ReclaimedBytes += ss.ReclaimedBytes
+		}
+		s.LoadFactor = float64(s.Entries) / float64(s.Buckets)
+		s.CacheHits, s.CacheMisses = i.cache.counts()
+		return s
+	}
+	s := Stats{
+		Entries: i.count(),
+		Records: len(i.records),
+	}
+	buckets, maxProbe, _ := i.tableStats()
+	s.Buckets, s.MaxProbe = buckets, maxProbe
+	s.LoadFactor = float64(s.Entries) / float64(s.Buckets)
+	s.KeyBytes, _ = i.keys.bytes()
+	s.MemBytes = i.memBytes()
+	s.ReclaimedBytes = i.reclaimed
+	s.CacheHits, s.CacheMisses = i.cache.counts()
+	return s
+}
+
+// memBytes estimates the bytes held by the key arena, records and table of
+// an unsharded, unmapped index.
+func (i *Index) memBytes() int64 {
+	_, arenaBytes := i.keys.bytes()
+	_, _, tableBytes := i.tableStats()
+	return arenaBytes + int64(cap(i.records))*int64(unsafe.Sizeof(record{})) + tableBytes
+}
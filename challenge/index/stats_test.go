@@ -0,0 +1,29 @@
+package index
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	idx := New()
+	for n := 0; n < 1000; n++ {
+		idx.Insert(fmt.Sprintf("key%04d", n), 1, 1)
+	}
+	idx.Insert("key0000", 2, 2)
+	idx.Delete("key0001")
+
+	s := idx.Stats()
+	if s.Entries != 999 || s.Records != 1001 {
+		t.Fatalf("Entries, Records = %d, %d; want 999, 1001", s.Entries, s.Records)
+	}
+	if s.KeyBytes != 1000*7 {
+		t.Fatalf("KeyBytes = %d, want %d", s.KeyBytes, 1000*7)
+	}
+	if s.Buckets < s.Entries || s.LoadFactor <= 0 || s.LoadFactor > 1 {
+		t.Fatalf("Buckets = %d, LoadFactor = %v", s.Buckets, s.LoadFactor)
+	}
+	if s.MemBytes <= s.KeyBytes {
+		t.Fatalf("MemBytes = %d, want > KeyBytes %d", s.MemBytes, s.KeyBytes)
+	}
+}
@@ -0,0 +1,214 @@
+//go:build !stdmap
+
+package index
+
+import (
+	"math/bits"
+	"unsafe"
+)
+
+// slot is one Robin Hood table entry. The key's hash is computed once on
+// insert and kept here, so probing and growth never rehash key bytes; the
+// key's arena reference is kept alongside so a hit does not chase the
+// record. Slots hold no pointers, so the GC never scans the table.
+type slot struct {
+	hash uint64
+	key  keyRef
+	pos  int32 // position in Index.records
+	dist int32 // probe distance from the home slot, plus one; 0 = empty
+}
+
+// table is an open-addressing hash table with Robin Hood probing: an
+// inserted entry takes over any slot whose occupant sits closer to its own
+// home, which keeps probe lengths short and lets a lookup stop as soon as
+// it meets an entry nearer home than itself. Deletes shift the following
+// run back by one, so there are no tombstones.
+type table struct {
+	slots []slot
+	shift uint // 64 - log2(len(slots))
+	n     int
+}
+
+// maxLoad is the fill fraction (7/8) at which the table doubles.
+func maxLoad(slots int) int { return slots - slots/8 }
+
+// home maps a hash to its preferred slot using Fibonacci hashing, which
+// takes the high bits of a multiplicative mix rather than FNV's weaker
+// low bits.
+func (t *table) home(h uint64) int {
+	return int((h * 0x9E3779B97F4A7C15) >> t.shift)
+}
+
+func (i *Index) tableInit(n int) {
+	c := slotsFor(n)
+	i.t = table{slots: make([]slot, c), shift: uint(64 - bits.TrailingZeros(uint(c)))}
+}
+
+func (i *Index) find(key string) (int32, bool) {
+	return i.findHashed(key, i.hash(key))
+}
+
+// findHashed is find with the key's hash already computed.
+func (i *Index) findHashed(key string, h uint64) (int32, bool) {
+	t := &i.t
+	mask := len(t.slots) - 1
+	for j, d := t.home(h), int32(1); ; j, d = (j+1)&mask, d+1 {
+		s := &t.slots[j]
+		if s.dist < d {
+			// Empty, or an entry closer to home than key would be:
+			// key would have displaced it, so key is absent.
+			return 0, false
+		}
+		if s.hash == h && i.keys.str(s.key) == key {
+			return s.pos, true
+		}
+	}
+}
+
+// set points key, whose hash is h, at pos and reports whether key was
+// already present. It returns key's arena reference: the existing one for
+// a present key, or a fresh copy of key appended to the arena.
+func (i *Index) set(key string, h uint64, pos int32) (keyRef, bool) {
+	t := &i.t
+	if t.n >= maxLoad(len(t.slots)) {
+		i.grow()
+	}
+	mask := len(t.slots) - 1
+	cur := slot{hash: h, pos: pos, dist: 1}
+	placed := false // cur has displaced an entry, so key is new
+	var ref keyRef
+	for j := t.home(h); ; j = (j + 1) & mask {
+		s := &t.slots[j]
+		if s.dist == 0 {
+			if !placed {
+				ref = i.addKey(key)
+				cur.key = ref
+			}
+			*s = cur
+			t.n++
+			return ref, false
+		}
+		// Until key has displaced something, a match can still lie
+		// ahead; after that the Robin Hood invariant rules it out.
+		if !placed && s.hash == h && i.keys.str(s.key) == key {
+			s.pos = pos
+			return s.key, true
+		}
+		if s.dist < cur.dist {
+			if !placed {
+				ref = i.addKey(key)
+				cur.key, placed = ref, true
+			}
+			*s, cur = cur, *s
+		}
+		cur.dist++
+	}
+}
+
+// place inserts a slot known not to be present, as during growth.
+func (t *table) place(cur slot) {
+	mask := len(t.slots) - 1
+	cur.dist = 1
+	for j := t.home(cur.hash); ; j = (j + 1) & mask {
+		s := &t.slots[j]
+		if s.dist == 0 {
+			*s = cur
+			t.n++
+			return
+		}
+		if s.dist < cur.dist {
+			*s, cur = cur, *s
+		}
+		cur.dist++
+	}
+}
+
+func (i *Index) grow() { i.resize(2 * len(i.t.slots)) }
+
+// tableReserve grows the table to hold n entries without further growth.
+func (i *Index) tableReserve(n int) {
+	if c := slotsFor(n); c > len(i.t.slots) {
+		i.resize(c)
+	}
+}
+
+// resize moves every entry into a table of c slots, a power of two.
+func (i *Index) resize(c int) {
+	old := i.t.slots
+	i.t = table{slots: make([]slot, c), shift: uint(64 - bits.TrailingZeros(uint(c)))}
+	for _, s := range old {
+		if s.dist != 0 {
+			i.t.place(s)
+		}
+	}
+}
+
+// unset removes key and reports whether it was present.
+func (i *Index) unset(key string) bool {
+	t := &i.t
+	h := i.hash(key)
+	mask := len(t.slots) - 1
+	j, d := t.home(h), int32(1)
+	for ; ; j, d = (j+1)&mask, d+1 {
+		s := &t.slots[j]
+		if s.dist < d {
+			return false
+		}
+		if s.hash == h && i.keys.str(s.key) == key {
+			break
+		}
+	}
+	// Backward-shift the rest of the run into the hole.
+	for {
+		next := (j + 1) & mask
+		if t.slots[next].dist <= 1 {
+			break
+		}
+		t.slots[j] = t.slots[next]
+		t.slots[j].dist--
+		j = next
+	}
+	t.slots[j] = slot{}
+	t.n--
+	return true
+}
+
+func (i *Index) count() int { return i.t.n }
+
+// tableClone returns a copy of the table that shares nothing with i.t.
+func (i *Index) tableClone() table {
+	t := i.t
+	t.slots = append([]slot(nil), t.slots...)
+	return t
+}
+
+// each calls fn with the record position of every live key, in table
+// order.
+func (i *Index) each(fn func(pos int32)) {
+	for p := range i.t.slots {
+		if s := &i.t.slots[p]; s.dist != 0 {
+			fn(s.pos)
+		}
+	}
+}
+
+// slotsFor returns the slot count tableInit picks for n entries.
+func slotsFor(n int) int {
+	c := 8
+	for maxLoad(c) < n {
+		c *= 2
+	}
+	return c
+}
+
+// tableBytesFor returns the bytes a table pre-sized for n entries holds.
+func tableBytesFor(n int) int64 { return int64(slotsFor(n)) * int64(unsafe.Sizeof(slot{})) }
+
+// tableStats reports the slot count, the longest probe sequence and the
+// bytes held by the table itself.
+func (i *Index) tableStats() (buckets, maxProbe int, bytes int64) {
+	for p := range i.t.slots {
+		maxProbe = max(maxProbe, int(i.t.slots[p].dist))
+	}
+	return len(i.t.slots), maxProbe, int64(len(i.t.slots)) * int64(unsafe.Sizeof(slot{}))
+}
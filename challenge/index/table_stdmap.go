@@ -0,0 +1,108 @@
+//go:build stdmap
+
+package index
+
+import "unsafe"
+
+// table is the built-in map, selected with -tags stdmap as a baseline for
+// the Robin Hood table. Its keys are views of the key arena, so there is
+// still no per-key allocation, but the map itself holds a pointer per key.
+type table struct {
+	m    map[string]int32
+	peak int // most keys ever live; the built-in map never shrinks
+}
+
+func (i *Index) tableInit(n int) {
+	i.t = table{m: make(map[string]int32, n), peak: n}
+}
+
+// tableReserve rebuilds the map sized for n keys if it was sized for fewer.
+func (i *Index) tableReserve(n int) {
+	if n <= i.t.peak {
+		return
+	}
+	m := make(map[string]int32, n)
+	for k, p := range i.t.m {
+		m[k] = p
+	}
+	i.t = table{m: m, peak: n}
+}
+
+func (i *Index) find(key string) (int32, bool) {
+	p, ok := i.t.m[key]
+	return p, ok
+}
+
+// findHashed is find for callers that already hashed key; the built-in
+// map hashes on its own, so a WithHasher hasher only feeds the Bloom
+// filter here.
+func (i *Index) findHashed(key string, _ uint64) (int32, bool) {
+	return i.find(key)
+}
+
+// set points key at pos and reports whether key was already present,
+// returning key's arena reference (appending a copy for a new key). h is
+// unused; the built-in map hashes on its own.
+func (i *Index) set(key string, _ uint64, pos int32) (keyRef, bool) {
+	if prev, ok := i.t.m[key]; ok {
+		i.t.m[key] = pos
+		return i.records[prev].key, true
+	}
+	ref := i.addKey(key)
+	i.t.m[i.keys.str(ref)] = pos
+	if len(i.t.m) > i.t.peak {
+		i.t.peak = len(i.t.m)
+	}
+	return ref, false
+}
+
+// unset removes key and reports whether it was present.
+func (i *Index) unset(key string) bool {
+	if _, ok := i.t.m[key]; !ok {
+		return false
+	}
+	delete(i.t.m, key)
+	return true
+}
+
+func (i *Index) count() int { return len(i.t.m) }
+
+// tableClone returns a copy of the table that shares nothing with i.t.
+func (i *Index) tableClone() table {
+	t := i.t
+	t.m = make(map[string]int32, len(i.t.m))
+	for k, p := range i.t.m {
+		t.m[k] = p
+	}
+	return t
+}
+
+// each calls fn with the record position of every live key, in map order.
+func (i *Index) each(fn func(pos int32)) {
+	for _, p := range i.t.m {
+		fn(p)
+	}
+}
+
+// tableStats estimates the built-in map's shape, which it does not expose:
+// slots are sized from the peak key count assuming 8-slot groups kept at
+// most 7/8 full, and the probe length is unknown (0).
+func (i *Index) tableStats() (buckets, maxProbe int, bytes int64) {
+	return bucketsFor(i.t.peak), 0, tableBytesFor(i.t.peak)
+}
+
+// bucketsFor estimates the slots the built-in map uses for n keys.
+func bucketsFor(n int) int {
+	buckets := 8
+	for buckets-buckets/8 < n {
+		buckets *= 2
+	}
+	return buckets
+}
+
+// tableBytesFor estimates the bytes the built-in map holds for n keys:
+// each slot holds a string header and an int32 position plus a control
+// byte.
+func tableBytesFor(n int) int64 {
+	return int64(bucketsFor(n)) * (int64(unsafe.Sizeof("")) + 4 + 1)
+}
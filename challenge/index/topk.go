@@ -0,0 +1,52 @@
+package index
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// TopBySize returns the k live entries with the largest size, largest
+// first; equal sizes are ordered by key so the result is deterministic.
+// It keeps a bounded min-heap of the best k seen so far, so it costs
+// O(n log k) time and O(k) extra memory. k <= 0 returns nil.
+func (i *Index) TopBySize(k int) []Entry {
+	if k <= 0 {
+		return nil
+	}
+	h := make(sizeHeap, 0, min(k, i.Len()))
+	i.eachEntry(func(e Entry) {
+		if len(h) < k {
+			heap.Push(&h, e)
+		} else if bySizeDesc(e, h[0]) {
+			h[0] = e
+			heap.Fix(&h, 0)
+		}
+	})
+	out := []Entry(h)
+	sort.Slice(out, func(a, b int) bool { return bySizeDesc(out[a], out[b]) })
+	return out
+}
+
+// bySizeDesc reports whether a ranks before b: larger size first, then
+// smaller key.
+func bySizeDesc(a, b Entry) bool {
+	if a.Size != b.Size {
+		return a.Size > b.Size
+	}
+	return a.Key < b.Key
+}
+
+// sizeHeap is a min-heap under bySizeDesc: the root is the worst-ranked
+// entry kept, the one a better candidate replaces.
+type sizeHeap []Entry
+
+func (h sizeHeap) Len() int           { return len(h) }
+func (h sizeHeap) Less(a, b int) bool { return bySizeDesc(h[b], h[a]) }
+func (h sizeHeap) Swap(a, b int)      { h[a], h[b] = h[b], h[a] }
+func (h *sizeHeap) Push(x any)        { *h = append(*h, x.(Entry)) }
+func (h *sizeHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
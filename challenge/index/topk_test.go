@@ -0,0 +1,40 @@
+package index
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestTopBySize(t *testing.T) {
+	idx := New()
+	var all []Entry
+	for n := 0; n < 500; n++ {
+		e := Entry{Key: fmt.Sprintf("key%03d", n), Size: uint64(n * 7 % 50), Offset: uint64(n)}
+		idx.Insert(e.Key, e.Size, e.Offset)
+		all = append(all, e)
+	}
+	sort.Slice(all, func(a, b int) bool { return bySizeDesc(all[a], all[b]) })
+
+	for _, k := range []int{0, 1, 10, 499, 500, 600} {
+		got := idx.TopBySize(k)
+		want := all[:min(k, len(all))]
+		if len(want) == 0 {
+			want = nil
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("TopBySize(%d) = %v, want %v", k, got, want)
+		}
+	}
+
+	path, _ := saveFile(t, idx)
+	m, err := OpenMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if got := m.TopBySize(10); !reflect.DeepEqual(got, all[:10]) {
+		t.Fatalf("mapped TopBySize(10) = %v, want %v", got, all[:10])
+	}
+}
@@ -0,0 +1,77 @@
+package index
+
+import "time"
+
+// WithClock makes the index read the current time from now instead of
+// time.Now when setting and checking InsertWithTTL deadlines, so tests can
+// expire entries without sleeping.
+func WithClock(now func() time.Time) Option {
+	return func(i *Index) { i.now = now }
+}
+
+// InsertWithTTL is InsertEntry of e under key (e.Key is ignored) that
+// expires ttl from now: from then on Get, Lookup and GetBatch report key
+// as absent, and the first Get to see it expired deletes it. A ttl of 0 or
+// less, like a plain Insert or InsertEntry over the key, means the entry
+// never expires.
+//
+// There is no background sweeper: until a Get or DeleteExpired removes
+// them, expired entries still count in Len and Stats, but every listing
+// (Keys, ForEach, the prefix, range and top-k queries, Diff and the
+// histogram), Merge and Save skip them. Deadlines stay with the index, its
+// snapshots and Merge; Save writes the unexpired entries without them, so
+// they load as permanent. A frozen index reports expired keys as absent
+// but cannot delete them.
+func (i *Index) InsertWithTTL(key string, e Entry, ttl time.Duration) {
+	e.Key = key
+	i.InsertEntry(e)
+	if ttl > 0 {
+		i.setDeadline(key, i.clock().Add(ttl))
+	}
+}
+
+// setDeadline makes the live key expire at d.
+func (i *Index) setDeadline(key string, d time.Time) {
+	if i.expires == nil {
+		i.expires = map[string]time.Time{}
+	}
+	i.expires[i.norm(key)] = d
+}
+
+// DeleteExpired deletes every expired entry and returns how many there
+// were. It panics on a frozen index.
+func (i *Index) DeleteExpired() int {
+	i.mustNotBeFrozen("DeleteExpired")
+	n := 0
+	for k := range i.expires {
+		if i.expired(k) {
+			i.Delete(k)
+			n++
+		}
+	}
+	return n
+}
+
+// expired reports whether the normalized key has a deadline that has
+// passed.
+func (i *Index) expired(key string) bool {
+	if i.expires == nil {
+		return false
+	}
+	d, ok := i.expires[key]
+	return ok && !i.clock().Before(d)
+}
+
+// evict deletes the expired, normalized key unless the index is frozen.
+func (i *Index) evict(key string) {
+	if !i.frozen {
+		i.Delete(key)
+	}
+}
+
+func (i *Index) clock() time.Time {
+	if i.now == nil {
+		return time.Now()
+	}
+	return i.now()
+}
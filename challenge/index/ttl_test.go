@@ -0,0 +1,149 @@
+package index
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInsertWithTTL(t *testing.T) {
+	now := time.Unix(1000, 0)
+	idx := New(WithClock(func() time.Time { return now }))
+	idx.InsertWithTTL("short", Entry{Size: 1, Offset: 1}, time.Millisecond)
+	idx.InsertWithTTL("long", Entry{Size: 2, Offset: 2}, time.Hour)
+	idx.InsertWithTTL("renewed", Entry{Size: 3, Offset: 3}, time.Millisecond)
+	idx.Insert("forever", 4, 4)
+
+	if size, _, ok := idx.Get("short"); !ok || size != 1 {
+		t.Fatalf("before expiry: Get(short) = %d, %v; want 1, true", size, ok)
+	}
+	idx.Insert("renewed", 5, 5) // a plain insert drops the deadline
+	now = now.Add(2 * time.Millisecond)
+
+	snap := idx.Snapshot()
+	if _, ok := idx.Lookup("short"); ok {
+		t.Fatal("Lookup found an expired key")
+	}
+	out := make([]Result, 4)
+	idx.GetBatch([]string{"short", "long", "renewed", "forever"}, out)
+	if want := []Result{{}, {2, 2, true}, {5, 5, true}, {4, 4, true}}; !reflect.DeepEqual(out, want) {
+		t.Fatalf("GetBatch = %v, want %v", out, want)
+	}
+	var seen []string
+	idx.ForEach(func(key string, size, offset uint64) bool {
+		seen = append(seen, key)
+		return true
+	})
+	if want := []string{"forever", "long", "renewed"}; !reflect.DeepEqual(seen, want) {
+		t.Fatalf("ForEach saw %q, want %q", seen, want)
+	}
+	if idx.Len() != 4 {
+		t.Fatalf("Len before eviction = %d, want 4", idx.Len())
+	}
+
+	// Merge drops the expired entry and keeps the live deadline.
+	merged := New(WithClock(func() time.Time { return now }))
+	merged.Merge(idx)
+	if _, ok := merged.Lookup("short"); ok || merged.Len() != 3 {
+		t.Fatalf("Merge: short found = %v, Len = %d; want false, 3", ok, merged.Len())
+	}
+	if _, _, ok := idx.Get("short"); ok {
+		t.Fatal("Get found an expired key")
+	}
+	if idx.Len() != 3 {
+		t.Fatalf("Len after Get evicted = %d, want 3", idx.Len())
+	}
+
+	// The frozen snapshot reports the key absent without deleting it.
+	if _, _, ok := snap.Get("short"); ok || snap.Len() != 4 {
+		t.Fatalf("snapshot: Get(short) ok = %v, Len = %d; want false, 4", ok, snap.Len())
+	}
+
+	now = now.Add(time.Hour)
+	if _, ok := merged.Lookup("long"); ok {
+		t.Fatal("Merge: long lost its deadline")
+	}
+	if n := idx.DeleteExpired(); n != 1 {
+		t.Fatalf("DeleteExpired = %d, want 1", n)
+	}
+	if got, want := idx.Keys(), []string{"forever", "renewed"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys = %q, want %q", got, want)
+	}
+}
+
+func TestTTLListings(t *testing.T) {
+	now := time.Unix(1000, 0)
+	build := func(opts ...Option) *Index {
+		idx := New(append(opts, WithClock(func() time.Time { return now }))...)
+		idx.Insert("k1", 5, 0)
+		idx.Insert("k2", 1, 10)
+		idx.InsertWithTTL("k3", Entry{Size: 9, Offset: 2}, time.Millisecond)
+		return idx
+	}
+	idx := build()
+	ranged := NewWithSizeIndex(0, WithClock(func() time.Time { return now }))
+	ranged.Merge(idx)
+	ranged.InsertWithTTL("k3", Entry{Size: 9, Offset: 2}, time.Millisecond)
+	ranged.SizeRange(0, 100) // build the size order while k3 is live
+	now = now.Add(time.Second)
+
+	live := []string{"k1", "k2"}
+	if got := idx.Keys(); !reflect.DeepEqual(got, live) {
+		t.Errorf("Keys = %q", got)
+	}
+	if got := idx.PrefixScan("k"); !reflect.DeepEqual(got, live) {
+		t.Errorf("PrefixScan = %q", got)
+	}
+	if got := idx.PrefixCounts(0); got[""] != 2 {
+		t.Errorf("PrefixCounts(0) = %v", got)
+	}
+	if got, _ := idx.Glob("k*"); !reflect.DeepEqual(got, live) {
+		t.Errorf("Glob = %q", got)
+	}
+	if got, ok := idx.Ceiling("k3"); ok {
+		t.Errorf("Ceiling(k3) = %q", got)
+	}
+	if got := idx.TopBySize(1); len(got) != 1 || got[0].Key != "k1" {
+		t.Errorf("TopBySize(1) = %v", got)
+	}
+	want := []Entry{{Key: "k2", Size: 1, Offset: 10}, {Key: "k1", Size: 5}}
+	for name, got := range map[string][]Entry{
+		"SizeRange":         idx.SizeRange(0, 100),
+		"indexed SizeRange": ranged.SizeRange(0, 100),
+	} {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	if got := idx.OffsetRange(0, 100); len(got) != 2 || got[0].Key != "k1" {
+		t.Errorf("OffsetRange = %v", got)
+	}
+	if h := idx.SizeHistogram(); h.Count != 2 || h.Max != 5 {
+		t.Errorf("SizeHistogram = %+v", h)
+	}
+	if got := idx.Overlaps(); len(got) != 0 {
+		t.Errorf("Overlaps = %q; k3 [2, 11) overlaps k1 and k2 but expired", got)
+	}
+	plain := New()
+	plain.Insert("k1", 5, 0)
+	plain.Insert("k2", 1, 10)
+	if d := Diff(plain, idx); len(d.Added)+len(d.Removed)+len(d.Modified) != 0 {
+		t.Errorf("Diff = %+v", d)
+	}
+
+	var buf bytes.Buffer
+	if err := idx.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := loaded.Get("k3"); ok || loaded.Len() != 2 {
+		t.Errorf("after Save and Load: k3 found = %v, Len = %d; want false, 2", ok, loaded.Len())
+	}
+	if idx.Len() != 3 {
+		t.Errorf("listings evicted: Len = %d, want 3", idx.Len())
+	}
+}
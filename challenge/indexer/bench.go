@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+// runBench is -bench: it times three phases separately and reports them on
+// w instead of printing answers.
+//
+//	parse  read and decode every blob and query line into memory
+//	build  insert the parsed blobs into a pre-sized index
+//	query  look up every query key
+//
+// The heap figures come from runtime.MemStats read right after a forced GC
+// once the build is done and the parsed blobs are dropped, so they show
+// what the index (plus the query keys) keeps, not parse garbage.
+func runBench(r *index.Reader, w io.Writer) error {
+	start := time.Now()
+	var ops []index.BlobOp
+	if err := r.ReadBlobOps(func(op index.BlobOp) error {
+		ops = append(ops, op)
+		return nil
+	}); err != nil {
+		return err
+	}
+	var keys []string
+	if err := r.ReadQueries(func(key string) error {
+		keys = append(keys, key)
+		return nil
+	}); err != nil {
+		return err
+	}
+	parse := time.Since(start)
+
+	start = time.Now()
+	idx := index.NewWithCapacity(len(ops))
+	for _, op := range ops {
+		if op.Delete {
+			idx.Delete(op.Key)
+		} else {
+			idx.Insert(op.Key, op.Size, op.Offset)
+		}
+	}
+	build := time.Since(start)
+	nops := len(ops)
+	ops = nil
+
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	start = time.Now()
+	found := 0
+	for _, k := range keys {
+		if _, _, ok := idx.Get(k); ok {
+			found++
+		}
+	}
+	query := time.Since(start)
+
+	fmt.Fprintf(w, "parse  %8d lines    %10v  %12.0f lines/s\n", nops+len(keys), parse, rate(nops+len(keys), parse))
+	fmt.Fprintf(w, "build  %8d entries  %10v  %12.0f entries/s\n", nops, build, rate(nops, build))
+	fmt.Fprintf(w, "query  %8d queries  %10v  %12.0f queries/s  (%d found)\n", len(keys), query, rate(len(keys), query), found)
+	fmt.Fprintf(w, "heap   live %d bytes after GC, sys %d bytes, %d keys\n", ms.HeapAlloc, ms.HeapSys, idx.Len())
+	return nil
+}
+
+func rate(n int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / d.Seconds()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"unicode/utf8"
+
+	_ "modernc.org/sqlite" // registers the pure-Go "sqlite" driver
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+// sqlBatchRows is how many INSERTs -export-sqlite groups into one
+// transaction; SQLite commits per statement otherwise, which is orders of
+// magnitude slower on a million rows.
+const sqlBatchRows = 10000
+
+// exportSQLite writes the entries of idx to a new SQLite database at path,
+// replacing any file there, in the table blobs(key TEXT PRIMARY KEY, size
+// INTEGER, offset INTEGER). Rows stream from ForEach in key order with a
+// transaction per sqlBatchRows rows, and the number written is returned.
+//
+// Keys that are not valid UTF-8 are stored as BLOBs so they keep their
+// exact bytes. A size or offset above MaxInt64 fails the export, since
+// SQLite's INTEGER cannot hold it and the column would coerce even a TEXT
+// value to a lossy REAL.
+func exportSQLite(idx *index.Index, path string) (int, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := insertBlobs(db, idx)
+	if cerr := db.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// Earlier batches are committed; drop the partial database.
+		os.Remove(path)
+	}
+	return rows, err
+}
+
+// insertBlobs creates the blobs table in db and fills it from idx.
+func insertBlobs(db *sql.DB, idx *index.Index) (int, error) {
+	if _, err := db.Exec("CREATE TABLE blobs(key TEXT PRIMARY KEY, size INTEGER, offset INTEGER)"); err != nil {
+		return 0, err
+	}
+	var (
+		tx   *sql.Tx
+		stmt *sql.Stmt
+		err  error
+	)
+	rows := 0
+	idx.ForEach(func(key string, size, offset uint64) bool {
+		if rows%sqlBatchRows == 0 {
+			if tx != nil {
+				if err = tx.Commit(); err != nil {
+					return false
+				}
+			}
+			if tx, err = db.Begin(); err != nil {
+				return false
+			}
+			if stmt, err = tx.Prepare("INSERT INTO blobs VALUES(?, ?, ?)"); err != nil {
+				return false
+			}
+		}
+		var k any = key
+		if !utf8.ValidString(key) {
+			k = []byte(key)
+		}
+		if size > math.MaxInt64 || offset > math.MaxInt64 {
+			err = fmt.Errorf("-export-sqlite: key %q: size %d or offset %d does not fit SQLite's INTEGER", key, size, offset)
+			return false
+		}
+		if _, err = stmt.Exec(k, int64(size), int64(offset)); err != nil {
+			return false
+		}
+		rows++
+		return true
+	})
+	if err != nil {
+		if tx != nil {
+			tx.Rollback()
+		}
+		return 0, err
+	}
+	if tx != nil {
+		err = tx.Commit()
+	}
+	return rows, err
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+func TestExportSQLite(t *testing.T) {
+	idx := index.New()
+	idx.Insert("o'brien", 1, 2)
+	idx.Insert("a\x00b", 3, 4)
+	idx.Insert("k\xffse", 5, 1<<63-1)
+	path := filepath.Join(t.TempDir(), "blobs.db")
+	rows, err := exportSQLite(idx, path)
+	if err != nil || rows != 3 {
+		t.Fatalf("exportSQLite = %d, %v; want 3, nil", rows, err)
+	}
+
+	db := openDB(t, path)
+	res, err := db.Query("SELECT key, typeof(key), size, typeof(offset) FROM blobs ORDER BY size")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for res.Next() {
+		var key []byte
+		var keyType, offsetType string
+		var size int64
+		if err := res.Scan(&key, &keyType, &size, &offsetType); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%q %s %d %s", key, keyType, size, offsetType))
+	}
+	if err := res.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`"o'brien" text 1 integer`, `"a\x00b" text 3 integer`, `"k\xffse" blob 5 integer`}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("rows = %q, want %q", got, want)
+	}
+
+	idx.Insert("huge", 6, 1<<63)
+	if _, err := exportSQLite(idx, path); err == nil || !strings.Contains(err.Error(), `"huge"`) {
+		t.Fatalf("offset 1<<63: err = %v, want one naming the key", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("failed export left %s behind: %v", path, err)
+	}
+
+	// A transaction per sqlBatchRows rows, into a file that already exists.
+	idx = index.New()
+	for j := 0; j < sqlBatchRows+1; j++ {
+		idx.Insert(strings.Repeat("k", j%50)+string(rune('a'+j%26))+strings.Repeat("x", j/26), 1, 1)
+	}
+	if rows, err := exportSQLite(idx, path); err != nil || rows != idx.Len() {
+		t.Fatalf("exportSQLite = %d, %v; want %d rows", rows, err, idx.Len())
+	}
+	var n int
+	if err := openDB(t, path).QueryRow("SELECT count(*) FROM blobs").Scan(&n); err != nil || n != idx.Len() {
+		t.Fatalf("count = %d, %v; want %d", n, err, idx.Len())
+	}
+}
+
+func openDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+	"github.com/quadgate/fluxor-blob/challenge/indexer/indexerpb"
+)
+
+// grpcServer implements the Index service of indexer.proto on a frozen
+// index, so RPCs may run concurrently like the -serve handlers.
+type grpcServer struct {
+	indexerpb.UnimplementedIndexServer
+	idx *index.Index
+}
+
+// newGRPCServer returns a gRPC server for -grpc with the Index service
+// registered on idx, which must be frozen.
+func newGRPCServer(idx *index.Index) *grpc.Server {
+	s := grpc.NewServer()
+	indexerpb.RegisterIndexServer(s, &grpcServer{idx: idx})
+	return s
+}
+
+func (s *grpcServer) Get(_ context.Context, req *indexerpb.GetRequest) (*indexerpb.GetResponse, error) {
+	return s.lookup(req.GetKey()), nil
+}
+
+// BatchGet answers each request as it arrives, so responses come back in
+// request order, until the client closes its side of the stream.
+func (s *grpcServer) BatchGet(stream grpc.BidiStreamingServer[indexerpb.GetRequest, indexerpb.GetResponse]) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(s.lookup(req.GetKey())); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *grpcServer) Stats(context.Context, *indexerpb.StatsRequest) (*indexerpb.StatsResponse, error) {
+	st := s.idx.Stats()
+	return &indexerpb.StatsResponse{
+		Entries:    int64(st.Entries),
+		Records:    int64(st.Records),
+		Buckets:    int64(st.Buckets),
+		LoadFactor: st.LoadFactor,
+		MaxProbe:   int64(st.MaxProbe),
+		KeyBytes:   st.KeyBytes,
+		MemBytes:   st.MemBytes,
+	}, nil
+}
+
+// lookup is Get of key as a response; size and offset stay 0 on a miss.
+func (s *grpcServer) lookup(key []byte) *indexerpb.GetResponse {
+	size, offset, found := s.idx.Get(string(key))
+	return &indexerpb.GetResponse{Key: key, Found: found, Size: size, Offset: offset}
+}
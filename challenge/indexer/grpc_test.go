@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+	"github.com/quadgate/fluxor-blob/challenge/indexer/indexerpb"
+)
+
+func TestGRPCServer(t *testing.T) {
+	idx := index.New()
+	idx.Insert("foo", 123, 456)
+	idx.Insert("a\x00\xff", 1, 2)
+	idx.Freeze()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := newGRPCServer(idx)
+	go srv.Serve(lis)
+	defer srv.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := indexerpb.NewIndexClient(conn)
+	ctx := context.Background()
+
+	resp, err := client.Get(ctx, &indexerpb.GetRequest{Key: []byte("foo")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&indexerpb.GetResponse{Key: []byte("foo"), Found: true, Size: 123, Offset: 456}); !proto.Equal(resp, want) {
+		t.Fatalf("Get(foo) = %v, want %v", resp, want)
+	}
+
+	stream, err := client.BatchGet(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{"a\x00\xff", "bar", "foo", ""}
+	for _, k := range keys {
+		if err := stream.Send(&indexerpb.GetRequest{Key: []byte(k)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	want := []*indexerpb.GetResponse{
+		{Key: []byte("a\x00\xff"), Found: true, Size: 1, Offset: 2},
+		{Key: []byte("bar")},
+		{Key: []byte("foo"), Found: true, Size: 123, Offset: 456},
+		{},
+	}
+	for j := 0; ; j++ {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			if j != len(want) {
+				t.Fatalf("BatchGet: %d responses, want %d", j, len(want))
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j >= len(want) || !proto.Equal(resp, want[j]) {
+			t.Fatalf("BatchGet response %d = %v, want %v", j, resp, want[min(j, len(want)-1)])
+		}
+	}
+
+	st, err := client.Stats(ctx, &indexerpb.StatsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := idx.Stats(); st.GetEntries() != 2 || st.GetBuckets() != int64(s.Buckets) || st.GetMemBytes() != s.MemBytes {
+		t.Fatalf("Stats = %v, want entries 2 and %+v", st, s)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/quadgate/fluxor-blob/challenge/gen"
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+// hashers are the weak hashes -hasher can swap in, each the one a gen
+// -adversarial mode targets, so the collisions reach the table's slots.
+var hashers = map[string]func(key string) uint64{
+	gen.AdvSum:   sumHash,
+	gen.AdvFNV1a: maskedFNV,
+}
+
+// sumHash is the byte sum of key.
+func sumHash(key string) uint64 {
+	var h uint64
+	for j := 0; j < len(key); j++ {
+		h += uint64(key[j])
+	}
+	return h
+}
+
+// maskedFNV is the index's FNV-1a cut to the low gen.AdvFNV1aBits bits, as
+// a table indexing with h&(size-1) would see it.
+func maskedFNV(key string) uint64 {
+	return index.FNV1a(key) & (1<<gen.AdvFNV1aBits - 1)
+}
+
+// hasherOption returns the index option for -hasher=name; "" keeps the
+// default hash.
+func hasherOption(name string) (index.Option, error) {
+	if name == "" {
+		return nil, nil
+	}
+	h, ok := hashers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown -hasher %q (want %s or %s)", name, gen.AdvSum, gen.AdvFNV1a)
+	}
+	return index.WithHasher(h), nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/quadgate/fluxor-blob/challenge/gen"
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+func TestHasherMaxProbe(t *testing.T) {
+	for _, name := range []string{gen.AdvSum, gen.AdvFNV1a} {
+		cfg := gen.DefaultConfig()
+		cfg.N, cfg.Q, cfg.Adversarial = 2000, 0, name
+		var input bytes.Buffer
+		if err := gen.Generate(&input, cfg); err != nil {
+			t.Fatal(err)
+		}
+		opt, err := hasherOption(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		base, err := index.NewReader(bytes.NewReader(input.Bytes())).ReadIndex()
+		if err != nil {
+			t.Fatal(err)
+		}
+		weak, err := index.NewReader(bytes.NewReader(input.Bytes())).ReadIndex(opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, w := base.Stats().MaxProbe, weak.Stats().MaxProbe
+		if b == 0 {
+			t.Skip("the table does not report probe lengths")
+		}
+		if w < cfg.N/2 {
+			t.Errorf("-hasher=%s: max_probe %d (default hash %d), want at least %d", name, w, b, cfg.N/2)
+		}
+	}
+	if _, err := hasherOption("md5"); err == nil {
+		t.Error("hasherOption(md5) succeeded")
+	}
+}
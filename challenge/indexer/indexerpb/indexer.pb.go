@@ -0,0 +1,361 @@
+// indexer.proto - gRPC interface to a built index, mirroring the HTTP
+// -serve mode (GET /get and GET /metrics) with a streaming batch lookup.
+//
+// The generated stubs are checked in under indexerpb; after changing this
+// file, regenerate them from this directory with:
+//
+//	protoc --go_out=indexerpb --go_opt=paths=source_relative \
+//	    --go-grpc_out=indexerpb --go-grpc_opt=paths=source_relative indexer.proto
+//
+// indexer -grpc serves it (see grpcServer), answering every RPC with
+// Index.Get on the frozen index, the same concurrent-safe path newServer
+// uses.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: indexer.proto
+
+package indexerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Keys are arbitrary bytes, as in the binary input format.
+	Key           []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_indexer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetRequest) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+type GetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           []byte                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Found         bool                   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	Size          uint64                 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`     // 0 when not found
+	Offset        uint64                 `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"` // 0 when not found
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResponse) Reset() {
+	*x = GetResponse{}
+	mi := &file_indexer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResponse) ProtoMessage() {}
+
+func (x *GetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetResponse) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *GetResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *GetResponse) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *GetResponse) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type StatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	mi := &file_indexer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRequest) ProtoMessage() {}
+
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{2}
+}
+
+type StatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       int64                  `protobuf:"varint,1,opt,name=entries,proto3" json:"entries,omitempty"`
+	Records       int64                  `protobuf:"varint,2,opt,name=records,proto3" json:"records,omitempty"`
+	Buckets       int64                  `protobuf:"varint,3,opt,name=buckets,proto3" json:"buckets,omitempty"`
+	LoadFactor    float64                `protobuf:"fixed64,4,opt,name=load_factor,json=loadFactor,proto3" json:"load_factor,omitempty"`
+	MaxProbe      int64                  `protobuf:"varint,5,opt,name=max_probe,json=maxProbe,proto3" json:"max_probe,omitempty"`
+	KeyBytes      int64                  `protobuf:"varint,6,opt,name=key_bytes,json=keyBytes,proto3" json:"key_bytes,omitempty"`
+	MemBytes      int64                  `protobuf:"varint,7,opt,name=mem_bytes,json=memBytes,proto3" json:"mem_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsResponse) Reset() {
+	*x = StatsResponse{}
+	mi := &file_indexer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsResponse) ProtoMessage() {}
+
+func (x *StatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StatsResponse) GetEntries() int64 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetRecords() int64 {
+	if x != nil {
+		return x.Records
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetBuckets() int64 {
+	if x != nil {
+		return x.Buckets
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetLoadFactor() float64 {
+	if x != nil {
+		return x.LoadFactor
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetMaxProbe() int64 {
+	if x != nil {
+		return x.MaxProbe
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetKeyBytes() int64 {
+	if x != nil {
+		return x.KeyBytes
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetMemBytes() int64 {
+	if x != nil {
+		return x.MemBytes
+	}
+	return 0
+}
+
+var File_indexer_proto protoreflect.FileDescriptor
+
+const file_indexer_proto_rawDesc = "" +
+	"\n" +
+	"\rindexer.proto\x12\x15fluxorblob.indexer.v1\"\x1e\n" +
+	"\n" +
+	"GetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\fR\x03key\"a\n" +
+	"\vGetResponse\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\fR\x03key\x12\x14\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x04R\x04size\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x04R\x06offset\"\x0e\n" +
+	"\fStatsRequest\"\xd5\x01\n" +
+	"\rStatsResponse\x12\x18\n" +
+	"\aentries\x18\x01 \x01(\x03R\aentries\x12\x18\n" +
+	"\arecords\x18\x02 \x01(\x03R\arecords\x12\x18\n" +
+	"\abuckets\x18\x03 \x01(\x03R\abuckets\x12\x1f\n" +
+	"\vload_factor\x18\x04 \x01(\x01R\n" +
+	"loadFactor\x12\x1b\n" +
+	"\tmax_probe\x18\x05 \x01(\x03R\bmaxProbe\x12\x1b\n" +
+	"\tkey_bytes\x18\x06 \x01(\x03R\bkeyBytes\x12\x1b\n" +
+	"\tmem_bytes\x18\a \x01(\x03R\bmemBytes2\x80\x02\n" +
+	"\x05Index\x12L\n" +
+	"\x03Get\x12!.fluxorblob.indexer.v1.GetRequest\x1a\".fluxorblob.indexer.v1.GetResponse\x12U\n" +
+	"\bBatchGet\x12!.fluxorblob.indexer.v1.GetRequest\x1a\".fluxorblob.indexer.v1.GetResponse(\x010\x01\x12R\n" +
+	"\x05Stats\x12#.fluxorblob.indexer.v1.StatsRequest\x1a$.fluxorblob.indexer.v1.StatsResponseB=Z;github.com/quadgate/fluxor-blob/challenge/indexer/indexerpbb\x06proto3"
+
+var (
+	file_indexer_proto_rawDescOnce sync.Once
+	file_indexer_proto_rawDescData []byte
+)
+
+func file_indexer_proto_rawDescGZIP() []byte {
+	file_indexer_proto_rawDescOnce.Do(func() {
+		file_indexer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_indexer_proto_rawDesc), len(file_indexer_proto_rawDesc)))
+	})
+	return file_indexer_proto_rawDescData
+}
+
+var file_indexer_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_indexer_proto_goTypes = []any{
+	(*GetRequest)(nil),    // 0: fluxorblob.indexer.v1.GetRequest
+	(*GetResponse)(nil),   // 1: fluxorblob.indexer.v1.GetResponse
+	(*StatsRequest)(nil),  // 2: fluxorblob.indexer.v1.StatsRequest
+	(*StatsResponse)(nil), // 3: fluxorblob.indexer.v1.StatsResponse
+}
+var file_indexer_proto_depIdxs = []int32{
+	0, // 0: fluxorblob.indexer.v1.Index.Get:input_type -> fluxorblob.indexer.v1.GetRequest
+	0, // 1: fluxorblob.indexer.v1.Index.BatchGet:input_type -> fluxorblob.indexer.v1.GetRequest
+	2, // 2: fluxorblob.indexer.v1.Index.Stats:input_type -> fluxorblob.indexer.v1.StatsRequest
+	1, // 3: fluxorblob.indexer.v1.Index.Get:output_type -> fluxorblob.indexer.v1.GetResponse
+	1, // 4: fluxorblob.indexer.v1.Index.BatchGet:output_type -> fluxorblob.indexer.v1.GetResponse
+	3, // 5: fluxorblob.indexer.v1.Index.Stats:output_type -> fluxorblob.indexer.v1.StatsResponse
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_indexer_proto_init() }
+func file_indexer_proto_init() {
+	if File_indexer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_indexer_proto_rawDesc), len(file_indexer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_indexer_proto_goTypes,
+		DependencyIndexes: file_indexer_proto_depIdxs,
+		MessageInfos:      file_indexer_proto_msgTypes,
+	}.Build()
+	File_indexer_proto = out.File
+	file_indexer_proto_goTypes = nil
+	file_indexer_proto_depIdxs = nil
+}
@@ -0,0 +1,215 @@
+// indexer.proto - gRPC interface to a built index, mirroring the HTTP
+// -serve mode (GET /get and GET /metrics) with a streaming batch lookup.
+//
+// The generated stubs are checked in under indexerpb; after changing this
+// file, regenerate them from this directory with:
+//
+//	protoc --go_out=indexerpb --go_opt=paths=source_relative \
+//	    --go-grpc_out=indexerpb --go-grpc_opt=paths=source_relative indexer.proto
+//
+// indexer -grpc serves it (see grpcServer), answering every RPC with
+// Index.Get on the frozen index, the same concurrent-safe path newServer
+// uses.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: indexer.proto
+
+package indexerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Index_Get_FullMethodName      = "/fluxorblob.indexer.v1.Index/Get"
+	Index_BatchGet_FullMethodName = "/fluxorblob.indexer.v1.Index/BatchGet"
+	Index_Stats_FullMethodName    = "/fluxorblob.indexer.v1.Index/Stats"
+)
+
+// IndexClient is the client API for Index service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IndexClient interface {
+	// Get looks up a single key.
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	// BatchGet answers each request on the stream with one response, in
+	// order, so a client can pipeline millions of lookups over one call
+	// instead of paying per-RPC overhead.
+	BatchGet(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[GetRequest, GetResponse], error)
+	// Stats reports the index statistics, as -stats prints them.
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+}
+
+type indexClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIndexClient(cc grpc.ClientConnInterface) IndexClient {
+	return &indexClient{cc}
+}
+
+func (c *indexClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, Index_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexClient) BatchGet(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[GetRequest, GetResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Index_ServiceDesc.Streams[0], Index_BatchGet_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetRequest, GetResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Index_BatchGetClient = grpc.BidiStreamingClient[GetRequest, GetResponse]
+
+func (c *indexClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, Index_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IndexServer is the server API for Index service.
+// All implementations must embed UnimplementedIndexServer
+// for forward compatibility.
+type IndexServer interface {
+	// Get looks up a single key.
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	// BatchGet answers each request on the stream with one response, in
+	// order, so a client can pipeline millions of lookups over one call
+	// instead of paying per-RPC overhead.
+	BatchGet(grpc.BidiStreamingServer[GetRequest, GetResponse]) error
+	// Stats reports the index statistics, as -stats prints them.
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	mustEmbedUnimplementedIndexServer()
+}
+
+// UnimplementedIndexServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIndexServer struct{}
+
+func (UnimplementedIndexServer) Get(context.Context, *GetRequest) (*GetResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedIndexServer) BatchGet(grpc.BidiStreamingServer[GetRequest, GetResponse]) error {
+	return status.Error(codes.Unimplemented, "method BatchGet not implemented")
+}
+func (UnimplementedIndexServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedIndexServer) mustEmbedUnimplementedIndexServer() {}
+func (UnimplementedIndexServer) testEmbeddedByValue()               {}
+
+// UnsafeIndexServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IndexServer will
+// result in compilation errors.
+type UnsafeIndexServer interface {
+	mustEmbedUnimplementedIndexServer()
+}
+
+func RegisterIndexServer(s grpc.ServiceRegistrar, srv IndexServer) {
+	// If the following call panics, it indicates UnimplementedIndexServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Index_ServiceDesc, srv)
+}
+
+func _Index_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Index_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Index_BatchGet_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IndexServer).BatchGet(&grpc.GenericServerStream[GetRequest, GetResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Index_BatchGetServer = grpc.BidiStreamingServer[GetRequest, GetResponse]
+
+func _Index_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Index_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Index_ServiceDesc is the grpc.ServiceDesc for Index service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Index_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fluxorblob.indexer.v1.Index",
+	HandlerType: (*IndexServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _Index_Get_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _Index_Stats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchGet",
+			Handler:       _Index_BatchGet_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "indexer.proto",
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+// Blob line formats for -format.
+const (
+	formatText = "text"
+	formatTSV  = "tsv"
+	formatCSV  = "csv"
+)
+
+// setFormat configures r for the -format and -header flags.
+func setFormat(r *index.Reader, format string, header bool) error {
+	switch format {
+	case formatText:
+		if header {
+			return errors.New("-header needs -format=tsv or csv")
+		}
+	case formatTSV:
+		r.SetDelimited('\t', header)
+	case formatCSV:
+		r.SetDelimited(',', header)
+	default:
+		return fmt.Errorf("unknown input format %q (want text, tsv or csv)", format)
+	}
+	return nil
+}
+
+// gzipMagic opens every gzip stream (RFC 1952).
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// openInput returns r, decompressed if it starts with the gzip magic bytes.
+// Sniffing rather than checking a file suffix means piped input (gen
+// -gzip | indexer) is detected too. Input that cannot be gzip, such as the
+// text format, which starts with a digit, passes through unchanged.
+func openInput(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	head, err := br.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !bytes.Equal(head, gzipMagic) {
+		return br, nil
+	}
+	return gzip.NewReader(br)
+}
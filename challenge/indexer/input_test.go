@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/quadgate/fluxor-blob/challenge/gen"
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+// answer runs input through openInput and the text-format query loop,
+// returning the printed answers.
+func answer(t *testing.T, input []byte) string {
+	t.Helper()
+	in, err := openInput(bytes.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := index.NewReader(in)
+	idx, err := r.ReadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	out, _ := newResultSink(outputText, &buf)
+	if err := r.ReadQueries(func(key string) error {
+		size, offset, ok := idx.Get(key)
+		return out.WriteResult(key, size, offset, ok)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestOpenInputGzip(t *testing.T) {
+	cfg := gen.DefaultConfig()
+	cfg.N, cfg.Q = 2000, 500
+	var plain, zipped bytes.Buffer
+	if err := gen.Generate(&plain, cfg); err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(&zipped)
+	zw.Write(plain.Bytes())
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := answer(t, plain.Bytes())
+	if got := answer(t, zipped.Bytes()); got != want {
+		t.Fatal("gzipped input answered differently from plain input")
+	}
+	if want == "" {
+		t.Fatal("no answers")
+	}
+
+	// Too short to sniff, and a corrupt gzip header.
+	if in, err := openInput(bytes.NewReader([]byte("0"))); err != nil || in == nil {
+		t.Fatalf("1-byte input: %v", err)
+	}
+	if _, err := openInput(bytes.NewReader([]byte{0x1f, 0x8b, 0})); err == nil {
+		t.Fatal("truncated gzip header: expected error")
+	}
+}
+
+func TestSetFormat(t *testing.T) {
+	in := "2\nkey,size,offset\n\"a,b\",1,2\nc,3,4\n2\na,b\nx\n"
+	r := index.NewReader(bytes.NewReader([]byte(in)))
+	if err := setFormat(r, formatCSV, true); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := r.ReadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size, offset, ok := idx.Get("a,b"); !ok || size != 1 || offset != 2 {
+		t.Fatalf("Get(a,b) = %d, %d, %v", size, offset, ok)
+	}
+
+	for _, bad := range []struct {
+		format string
+		header bool
+	}{{formatText, true}, {"xml", false}} {
+		if err := setFormat(index.NewReader(bytes.NewReader(nil)), bad.format, bad.header); err == nil {
+			t.Errorf("format %q header %v: expected error", bad.format, bad.header)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// indexer - Go Fast Blob Indexer driving package index
+// Usage: go run ./challenge/indexer < input.txt > output.txt
+//
+// Reads the challenge text format from stdin (N, N lines "key size offset",
+// Q, Q lines "key"), gunzipping it first if it starts with the gzip magic
+// bytes (as written by gen -gzip), and prints "size offset" or "NOTFOUND"
+// per query through a single buffered writer, or with -output=json one
+// object per line: {"key":"foo","found":true,"size":1,"offset":2}, with
+// "key_b64" in place of "key" for keys that are not valid UTF-8.
+// A blob line may start with an opcode: "+ key size offset" inserts (same
+// as no opcode) and "- key" deletes. Input is streamed: blobs go straight
+// into the index and queries are answered as they are read.
+//
+// Flags:
+//
+//	-cache N   front Get with an N-entry LRU cache (see index.WithCache),
+//	           whose hits and misses -serve reports on /metrics; not with
+//	           -load
+//	-hasher H  hash keys with the weak hash that gen -adversarial=H
+//	           collides, sum or fnv1a (FNV-1a cut to its low bits); not
+//	           with -load
+//	-stats     print index statistics to stderr after the build
+//	-parallel  read all queries first, answer them across GOMAXPROCS
+//	           goroutines and print the results in input order
+//	-save F    after building, write the index to F (see index.Save)
+//	-load F    load the index from F instead of reading blobs; stdin then
+//	           holds only the query section (Q, Q lines "key")
+//	-mmap      with -load, serve lookups from a memory mapping of F
+//	-output F  text (default) or json
+//	-verify F  compare each answer with the expected answers file F (gen
+//	           -answers) instead of printing; reports the first mismatch
+//	           and exits 1 on any mismatch or a length mismatch
+//	-bench     time the parse, build and query phases separately and print
+//	           throughput and heap use to stderr instead of answers
+//	-serve A   after building, serve GET /get?key=K and GET /metrics on
+//	           address A (e.g. :8080) instead of reading queries; see
+//	           newServer
+//	-grpc A    after building, serve the Index service of indexer.proto on
+//	           address A instead of reading queries; see newGRPCServer
+//	-format F  blob line format: text (default), tsv or csv; tsv and csv
+//	           rows are "key,size,offset" with encoding/csv quoting, and the
+//	           N and Q lines and queries keep the text format
+//	-header    with -format=tsv or csv, skip a header row after the N line
+//	-glob P    after building, print the keys matching the path.Match
+//	           pattern P, one per line in sorted order, instead of reading
+//	           queries
+//	-export-sqlite F
+//	           after building, write every entry to a new SQLite database
+//	           F instead of reading queries; see exportSQLite
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+func main() {
+	cacheSize := flag.Int("cache", 0, "front Get with an LRU cache of N entries")
+	hasher := flag.String("hasher", "", "hash keys with the weak hash gen -adversarial targets: sum or fnv1a")
+	stats := flag.Bool("stats", false, "print index statistics to stderr after the build")
+	parallel := flag.Bool("parallel", false, "answer queries across GOMAXPROCS goroutines")
+	savePath := flag.String("save", "", "write the built index to FILE")
+	loadPath := flag.String("load", "", "load the index from FILE; stdin holds only queries")
+	useMmap := flag.Bool("mmap", false, "with -load, memory-map FILE instead of reading it")
+	output := flag.String("output", outputText, "query result format: text or json")
+	verifyPath := flag.String("verify", "", "compare answers with the expected answers FILE instead of printing them")
+	bench := flag.Bool("bench", false, "report parse, build and query timings on stderr instead of answering")
+	serveAddr := flag.String("serve", "", "serve HTTP lookups on ADDR after the build instead of reading queries")
+	grpcAddr := flag.String("grpc", "", "serve gRPC lookups on ADDR after the build instead of reading queries")
+	format := flag.String("format", formatText, "blob line format: text, tsv or csv")
+	header := flag.Bool("header", false, "with -format=tsv or csv, skip the header row after N")
+	glob := flag.String("glob", "", "print the keys matching PATTERN (path.Match syntax) instead of reading queries")
+	exportPath := flag.String("export-sqlite", "", "write the entries to the SQLite database FILE instead of reading queries")
+	flag.Parse()
+
+	in, err := openInput(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "indexer: reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+	r := index.NewReader(in)
+	var out resultSink
+	err = setFormat(r, *format, *header)
+	if err == nil {
+		out, err = newResultSink(*output, os.Stdout)
+	}
+	if err == nil && *verifyPath != "" {
+		out, err = openVerifySink(*verifyPath)
+	}
+	if err == nil && *cacheSize > 0 && *loadPath != "" {
+		err = fmt.Errorf("-cache needs an index built from stdin, not -load")
+	}
+	var opts []index.Option
+	if *cacheSize > 0 {
+		opts = append(opts, index.WithCache(*cacheSize))
+	}
+	if err == nil && *hasher != "" {
+		var opt index.Option
+		if opt, err = hasherOption(*hasher); err == nil && *loadPath != "" {
+			err = fmt.Errorf("-hasher needs an index built from stdin, not -load")
+		}
+		opts = append(opts, opt)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "indexer: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *bench {
+		if err := runBench(r, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "indexer: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var idx *index.Index
+	if *loadPath != "" && *useMmap {
+		idx, err = index.OpenMmap(*loadPath)
+	} else if *loadPath != "" {
+		idx, err = loadIndex(*loadPath)
+	} else {
+		// ReadIndex pre-sizes the index from the leading N, up to a cap.
+		idx, err = r.ReadIndex(opts...)
+	}
+	if err == nil && *savePath != "" {
+		err = saveIndex(idx, *savePath)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "indexer: %v\n", err)
+		os.Exit(1)
+	}
+	if *stats {
+		s := idx.Stats()
+		fmt.Fprintf(os.Stderr, "entries=%d records=%d buckets=%d load=%.3f max_probe=%d key_bytes=%d mem_bytes=%d\n",
+			s.Entries, s.Records, s.Buckets, s.LoadFactor, s.MaxProbe, s.KeyBytes, s.MemBytes)
+	}
+	if *glob != "" {
+		err = printGlob(idx, *glob, os.Stdout)
+	} else if *exportPath != "" {
+		var rows int
+		if rows, err = exportSQLite(idx, *exportPath); err == nil {
+			fmt.Fprintf(os.Stderr, "indexer: wrote %d rows to %s\n", rows, *exportPath)
+		}
+	} else if *serveAddr != "" {
+		// The index is complete before the listener opens; freezing makes
+		// concurrent handler lookups safe.
+		idx.Freeze()
+		fmt.Fprintf(os.Stderr, "indexer: serving %d keys on %s\n", idx.Len(), *serveAddr)
+		err = http.ListenAndServe(*serveAddr, newServer(idx))
+	} else if *grpcAddr != "" {
+		idx.Freeze()
+		var lis net.Listener
+		if lis, err = net.Listen("tcp", *grpcAddr); err == nil {
+			fmt.Fprintf(os.Stderr, "indexer: serving %d keys over gRPC on %s\n", idx.Len(), lis.Addr())
+			err = newGRPCServer(idx).Serve(lis)
+		}
+	} else if *parallel {
+		err = answerParallel(r, idx, out)
+	} else {
+		err = r.ReadQueries(func(key string) error {
+			size, offset, ok := idx.Get(key)
+			return out.WriteResult(key, size, offset, ok)
+		})
+	}
+	if ferr := out.Flush(); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "indexer: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// answerParallel reads every query, answers them concurrently on the frozen
+// index and writes the results in query order.
+func answerParallel(r *index.Reader, idx *index.Index, out resultSink) error {
+	var keys []string
+	if err := r.ReadQueries(func(key string) error {
+		keys = append(keys, key)
+		return nil
+	}); err != nil {
+		return err
+	}
+	idx.Freeze()
+	results := make([]index.Result, len(keys))
+	idx.GetParallel(keys, results, 0)
+	for j, res := range results {
+		if err := out.WriteResult(keys[j], res.Size, res.Offset, res.Found); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadIndex(path string) (*index.Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return index.Load(f)
+}
+
+func saveIndex(idx *index.Index, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := idx.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// printGlob writes the keys of idx matching pattern, one per line.
+func printGlob(idx *index.Index, pattern string, w io.Writer) error {
+	keys, err := idx.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("-glob %q: %w", pattern, err)
+	}
+	bw := bufio.NewWriter(w)
+	for _, k := range keys {
+		bw.WriteString(k)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
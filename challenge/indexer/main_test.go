@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+func TestPrintGlob(t *testing.T) {
+	idx := index.New()
+	for _, k := range []string{"tmp_b", "img_1.jpg", "tmp_a"} {
+		idx.Insert(k, 1, 1)
+	}
+	var buf bytes.Buffer
+	if err := printGlob(idx, "tmp_*", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "tmp_a\ntmp_b\n"; got != want {
+		t.Fatalf("printGlob = %q, want %q", got, want)
+	}
+	if err := printGlob(idx, "[", &buf); err == nil {
+		t.Fatal("bad pattern: expected error")
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the /get latency
+// histogram. An in-memory lookup takes well under a microsecond, so the
+// buckets mostly resolve HTTP and encoding overhead.
+var latencyBuckets = [...]float64{1e-6, 2.5e-6, 5e-6, 1e-5, 2.5e-5, 5e-5, 1e-4, 2.5e-4, 1e-3, 1e-2}
+
+// metrics counts /get traffic for /metrics. Every field is updated with a
+// single atomic add, so concurrent handlers never contend on a lock.
+type metrics struct {
+	hits, misses atomic.Uint64
+	buckets      [len(latencyBuckets) + 1]atomic.Uint64 // per bucket, not cumulative; the last is +Inf
+	sumNanos     atomic.Uint64
+}
+
+// observe records one lookup that took d.
+func (m *metrics) observe(d time.Duration, found bool) {
+	if found {
+		m.hits.Add(1)
+	} else {
+		m.misses.Add(1)
+	}
+	b := 0
+	for b < len(latencyBuckets) && d.Seconds() > latencyBuckets[b] {
+		b++
+	}
+	m.buckets[b].Add(1)
+	m.sumNanos.Add(uint64(d))
+}
+
+// writeProm writes the counters, and the gauges taken from s, in the
+// Prometheus text exposition format.
+func (m *metrics) writeProm(w io.Writer, s index.Stats) error {
+	hits, misses := m.hits.Load(), m.misses.Load()
+	p := promWriter{w: w}
+	p.metric("indexer_queries_total", "counter", "Lookups served by /get.", hits+misses)
+	p.metric("indexer_query_hits_total", "counter", "Lookups that found their key.", hits)
+	p.metric("indexer_query_misses_total", "counter", "Lookups that did not find their key.", misses)
+	p.metric("indexer_cache_hits_total", "counter", "Lookups answered by the index's LRU cache, if any.", s.CacheHits)
+	p.metric("indexer_cache_misses_total", "counter", "Lookups that missed the index's LRU cache, if any.", s.CacheMisses)
+	p.metric("indexer_index_entries", "gauge", "Distinct keys in the index.", uint64(s.Entries))
+
+	const name = "indexer_get_duration_seconds"
+	p.printf("# HELP %s Time to look up and encode one /get answer.\n# TYPE %s histogram\n", name, name)
+	var count uint64
+	for b := range m.buckets {
+		count += m.buckets[b].Load()
+		le := "+Inf"
+		if b < len(latencyBuckets) {
+			le = strconv.FormatFloat(latencyBuckets[b], 'g', -1, 64)
+		}
+		p.printf("%s_bucket{le=%q} %d\n", name, le, count)
+	}
+	p.printf("%s_sum %g\n%s_count %d\n", name, time.Duration(m.sumNanos.Load()).Seconds(), name, count)
+	return p.err
+}
+
+// promWriter writes exposition lines to w, keeping the first write error.
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *promWriter) printf(format string, args ...any) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+func (p *promWriter) metric(name, typ, help string, v uint64) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, typ, name, v)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+// Output formats for -output.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// resultSink writes one answer per query in the selected -output format.
+type resultSink interface {
+	WriteResult(key string, size, offset uint64, found bool) error
+	Flush() error
+}
+
+func newResultSink(format string, w io.Writer) (resultSink, error) {
+	switch format {
+	case outputText:
+		return textSink{index.NewResultWriter(w)}, nil
+	case outputJSON:
+		bw := bufio.NewWriterSize(w, 1<<20)
+		enc := json.NewEncoder(bw)
+		enc.SetEscapeHTML(false)
+		return &jsonSink{bw: bw, enc: enc}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q (want text or json)", format)
+}
+
+// textSink is the challenge format: "size offset" or "NOTFOUND".
+type textSink struct{ *index.ResultWriter }
+
+func (s textSink) WriteResult(_ string, size, offset uint64, found bool) error {
+	return s.ResultWriter.WriteResult(size, offset, found)
+}
+
+// jsonResult is one -output=json line. Exactly one of Key and KeyB64 is
+// set: a JSON string cannot carry bytes that are not valid UTF-8 (gen
+// -binary-keys), so such keys are sent in standard base64 instead.
+type jsonResult struct {
+	Key    *string `json:"key,omitempty"`
+	KeyB64 string  `json:"key_b64,omitempty"`
+	getResponse
+}
+
+// jsonSink streams one JSON object per line through a buffered encoder.
+type jsonSink struct {
+	bw  *bufio.Writer
+	enc *json.Encoder
+}
+
+func (s *jsonSink) WriteResult(key string, size, offset uint64, found bool) error {
+	var res jsonResult
+	if utf8.ValidString(key) {
+		res.Key = &key
+	} else {
+		res.KeyB64 = base64.StdEncoding.EncodeToString([]byte(key))
+	}
+	if found {
+		res.getResponse = getResponse{Found: true, Size: &size, Offset: &offset}
+	}
+	return s.enc.Encode(res)
+}
+
+func (s *jsonSink) Flush() error { return s.bw.Flush() }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONSink(t *testing.T) {
+	var buf bytes.Buffer
+	out, err := newResultSink(outputJSON, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{"foo", "a \"quoted\"\tkey<&>", "miss", "é\x01", "\xff\x00a", "\xfe\x00a", ""}
+	for j, k := range keys {
+		if err := out.WriteResult(k, uint64(j), 2, k != "miss"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := out.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(keys) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(keys), buf.String())
+	}
+	if want := `{"key":"foo","found":true,"size":0,"offset":2}`; lines[0] != want {
+		t.Fatalf("line 0 = %s, want %s", lines[0], want)
+	}
+	if want := `{"key":"miss","found":false}`; lines[2] != want {
+		t.Fatalf("line 2 = %s, want %s", lines[2], want)
+	}
+	if want := `{"key_b64":"/wBh","found":true,"size":4,"offset":2}`; lines[4] != want {
+		t.Fatalf("line 4 = %s, want %s", lines[4], want)
+	}
+	// Every key, binary ones included, round-trips exactly.
+	for j, l := range lines {
+		var res jsonResult
+		if err := json.Unmarshal([]byte(l), &res); err != nil {
+			t.Fatalf("line %d = %s: %v", j, l, err)
+		}
+		var got string
+		if res.Key != nil {
+			got = *res.Key
+		} else {
+			b, err := base64.StdEncoding.DecodeString(res.KeyB64)
+			if err != nil {
+				t.Fatalf("line %d = %s: %v", j, l, err)
+			}
+			got = string(b)
+		}
+		if got != keys[j] || (res.Key != nil) == (res.KeyB64 != "") {
+			t.Fatalf("line %d = %s: decodes to %q, want exactly one of key and key_b64 holding %q", j, l, got, keys[j])
+		}
+	}
+
+	if _, err := newResultSink("xml", &buf); err == nil {
+		t.Fatal("unknown format: expected error")
+	}
+}
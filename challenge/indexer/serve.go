@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+// getResponse is the JSON body of /get. Size and Offset are omitted on a
+// miss.
+type getResponse struct {
+	Found  bool    `json:"found"`
+	Size   *uint64 `json:"size,omitempty"`
+	Offset *uint64 `json:"offset,omitempty"`
+}
+
+// newServer returns the HTTP handler for -serve:
+//
+//	GET /get?key=K  {"found":true,"size":S,"offset":O} or {"found":false}
+//	GET /metrics    Prometheus text format: query, hit and miss counters,
+//	                a /get latency histogram and Stats gauges
+//
+// A request without a key parameter gets 400; key= (empty) looks up the
+// empty key. idx must be frozen, since handlers run concurrently.
+func newServer(idx *index.Index) http.Handler {
+	var m metrics
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /get", func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		keys, ok := r.URL.Query()["key"]
+		if !ok {
+			http.Error(w, "missing key parameter", http.StatusBadRequest)
+			return
+		}
+		var resp getResponse
+		size, offset, found := idx.Get(keys[0])
+		if found {
+			resp = getResponse{Found: true, Size: &size, Offset: &offset}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+		m.observe(time.Since(start), found)
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.writeProm(w, idx.Stats())
+	})
+	return mux
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+func TestServer(t *testing.T) {
+	idx := index.New()
+	idx.Insert("foo", 123, 456)
+	idx.Insert("zero", 0, 0)
+	idx.Insert("a b", 1, 2)
+	idx.Freeze()
+	srv := newServer(idx)
+
+	tests := []struct {
+		target string
+		code   int
+		body   string
+	}{
+		{"/get?key=foo", 200, `{"found":true,"size":123,"offset":456}`},
+		{"/get?key=zero", 200, `{"found":true,"size":0,"offset":0}`},
+		{"/get?key=a+b", 200, `{"found":true,"size":1,"offset":2}`},
+		{"/get?key=bar", 200, `{"found":false}`},
+		{"/get", 400, "missing key parameter"},
+		{"/nope", 404, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rec.Code != tt.code {
+			t.Errorf("GET %s: status %d, want %d", tt.target, rec.Code, tt.code)
+		}
+		if got := strings.TrimSpace(rec.Body.String()); tt.body != "" && got != tt.body {
+			t.Errorf("GET %s: body %s, want %s", tt.target, got, tt.body)
+		}
+	}
+}
+
+func TestServerMetrics(t *testing.T) {
+	idx := index.New()
+	idx.Insert("foo", 1, 2)
+	idx.Freeze()
+	srv := newServer(idx)
+	for _, target := range []string{"/get?key=foo", "/get?key=foo", "/get?key=bar", "/get"} {
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != 200 || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("GET /metrics: status %d, Content-Type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE indexer_queries_total counter\nindexer_queries_total 3\n",
+		"\nindexer_query_hits_total 2\n",
+		"\nindexer_query_misses_total 1\n",
+		"\nindexer_index_entries 1\n",
+		"# TYPE indexer_get_duration_seconds histogram\n",
+		"\nindexer_get_duration_seconds_bucket{le=\"+Inf\"} 3\n",
+		"\nindexer_get_duration_seconds_count 3\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestServerCacheMetrics(t *testing.T) {
+	idx := index.New(index.WithCache(16))
+	idx.Insert("foo", 1, 2)
+	idx.Freeze()
+	srv := newServer(idx)
+	for _, target := range []string{"/get?key=foo", "/get?key=foo", "/get?key=bar", "/get?key=bar"} {
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{"\nindexer_cache_hits_total 2\n", "\nindexer_cache_misses_total 2\n"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/quadgate/fluxor-blob/challenge/index"
+)
+
+// verifySink compares each answer with the next line of an expected
+// answers file (gen -answers) instead of printing it. Flush reports the
+// outcome: nil and a PASS summary on stderr, or an error describing the
+// first mismatch.
+type verifySink struct {
+	sc      *bufio.Scanner
+	summary io.Writer
+
+	err       error // sticky: set once the expected stream runs out
+	n, bad    int
+	first     int // 1-based query number of the first mismatch
+	want, got string
+	buf       []byte
+}
+
+func newVerifySink(expected io.Reader, summary io.Writer) *verifySink {
+	sc := bufio.NewScanner(expected)
+	return &verifySink{sc: sc, summary: summary}
+}
+
+// WriteResult fails only when the expected stream runs out; a mismatch is
+// recorded and checking continues so the summary can count them all.
+func (s *verifySink) WriteResult(_ string, size, offset uint64, found bool) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.n++
+	if !s.sc.Scan() {
+		s.err = fmt.Errorf("verify: expected answers end after %d lines, but there are more queries", s.n-1)
+		if err := s.sc.Err(); err != nil {
+			s.err = fmt.Errorf("verify: reading expected answers: %w", err)
+		}
+		return s.err
+	}
+	s.buf = s.buf[:0]
+	if found {
+		s.buf = strconv.AppendUint(s.buf, size, 10)
+		s.buf = append(s.buf, ' ')
+		s.buf = strconv.AppendUint(s.buf, offset, 10)
+	} else {
+		s.buf = append(s.buf, index.NotFound...)
+	}
+	if want := bytes.TrimRight(s.sc.Bytes(), " \t\r"); !bytes.Equal(want, s.buf) {
+		if s.bad == 0 {
+			s.first, s.want, s.got = s.n, string(want), string(s.buf)
+		}
+		s.bad++
+	}
+	return nil
+}
+
+func (s *verifySink) Flush() error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.sc.Scan() {
+		return fmt.Errorf("verify: expected answers have more lines than the %d queries", s.n)
+	}
+	if err := s.sc.Err(); err != nil {
+		return fmt.Errorf("verify: reading expected answers: %w", err)
+	}
+	if s.bad > 0 {
+		return fmt.Errorf("verify: FAIL: %d of %d answers differ; first at query %d: expected %q, got %q",
+			s.bad, s.n, s.first, s.want, s.got)
+	}
+	fmt.Fprintf(s.summary, "verify: PASS: %d answers match\n", s.n)
+	return nil
+}
+
+// openVerifySink opens the expected answers file for -verify. The file is
+// left open for the life of the process.
+func openVerifySink(path string) (*verifySink, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return newVerifySink(f, os.Stderr), nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVerifySink(t *testing.T) {
+	type answer struct {
+		size, offset uint64
+		found        bool
+	}
+	got := []answer{{1, 2, true}, {0, 0, false}, {3, 4, true}}
+	tests := []struct {
+		name, expected, err string
+	}{
+		{"pass", "1 2\nNOTFOUND\n3 4\r\n", ""},
+		{"mismatch", "1 2\n5 6\n3 5\n", `2 of 3 answers differ; first at query 2: expected "5 6", got "NOTFOUND"`},
+		{"short", "1 2\nNOTFOUND\n", "end after 2 lines"},
+		{"long", "1 2\nNOTFOUND\n3 4\n7 8\n", "more lines than the 3 queries"},
+	}
+	for _, tt := range tests {
+		var summary bytes.Buffer
+		s := newVerifySink(strings.NewReader(tt.expected), &summary)
+		var err error
+		for _, a := range got {
+			if err = s.WriteResult("k", a.size, a.offset, a.found); err != nil {
+				break
+			}
+		}
+		if ferr := s.Flush(); err == nil {
+			err = ferr
+		} else if ferr != err {
+			t.Errorf("%s: Flush after error = %v, want %v", tt.name, ferr, err)
+		}
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.err == "" && !strings.Contains(summary.String(), "PASS: 3 answers"):
+			t.Errorf("%s: summary %q", tt.name, summary.String())
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: err = %v, want it to contain %q", tt.name, err, tt.err)
+		}
+	}
+}